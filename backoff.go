@@ -0,0 +1,165 @@
+package gosmpp
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBackoffInitial    = time.Second
+	defaultBackoffMax        = time.Minute
+	defaultBackoffMultiplier = 2
+)
+
+// BackoffPolicy decides delay between rebinding attempts of session.
+type BackoffPolicy interface {
+	// Next returns delay before next attempt, after given number of consecutive failed attempts.
+	// Returning false gives up rebinding.
+	Next(failed int) (delay time.Duration, ok bool)
+}
+
+// ConstantBackoff waits fixed interval between attempts.
+type ConstantBackoff struct {
+	Interval time.Duration
+
+	// MaxAttempts is maximum number of consecutive failed attempts before giving up.
+	// Zero means unlimited.
+	MaxAttempts int
+}
+
+// Next implements BackoffPolicy interface.
+func (b ConstantBackoff) Next(failed int) (time.Duration, bool) {
+	if b.MaxAttempts > 0 && failed >= b.MaxAttempts {
+		return 0, false
+	}
+	return b.Interval, true
+}
+
+// ExponentialBackoff multiplies delay after each failed attempt, up to Max.
+// Jitter spreads attempts of many sessions which lost their SMSC at the same time.
+type ExponentialBackoff struct {
+	// Initial delay after the first failed attempt.
+	//
+	// Default: 1 sec
+	Initial time.Duration
+
+	// Max caps delay, before jitter is applied.
+	//
+	// Default: 1 minute
+	Max time.Duration
+
+	// Multiplier of delay after each failed attempt.
+	//
+	// Default: 2
+	Multiplier float64
+
+	// Jitter is randomization factor in range [0, 1]. Delay is picked randomly
+	// from [delay * (1 - Jitter), delay * (1 + Jitter)].
+	//
+	// Zero means no jitter.
+	Jitter float64
+
+	// MaxAttempts is maximum number of consecutive failed attempts before giving up.
+	// Zero means unlimited.
+	MaxAttempts int
+}
+
+// Next implements BackoffPolicy interface.
+func (b ExponentialBackoff) Next(failed int) (time.Duration, bool) {
+	if b.MaxAttempts > 0 && failed >= b.MaxAttempts {
+		return 0, false
+	}
+
+	initial, max, multiplier := b.Initial, b.Max, b.Multiplier
+	if initial <= 0 {
+		initial = defaultBackoffInitial
+	}
+	if max <= 0 {
+		max = defaultBackoffMax
+	}
+	if multiplier < 1 {
+		multiplier = defaultBackoffMultiplier
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(failed-1))
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+
+	if jitter := math.Min(b.Jitter, 1); jitter > 0 {
+		delay *= 1 - jitter + 2*jitter*rand.Float64()
+	}
+
+	return time.Duration(delay), true
+}
+
+// rebinder runs rebinding attempts of session following BackoffPolicy.
+type rebinder struct {
+	attempts uint64 // total attempts, accessed atomically
+
+	policy   BackoffPolicy
+	onError  ErrorCallback
+	onGiveUp GiveUpCallback
+	closed   chan struct{}
+}
+
+func newRebinder(policy BackoffPolicy, onError ErrorCallback, onGiveUp GiveUpCallback) *rebinder {
+	return &rebinder{
+		policy:   policy,
+		onError:  onError,
+		onGiveUp: onGiveUp,
+		closed:   make(chan struct{}),
+	}
+}
+
+// run bind attempts until success. Returns false if rebinding is given up or rebinder is closed.
+func (r *rebinder) run(bind func() error) bool {
+	for failed := 0; ; {
+		select {
+		case <-r.closed:
+			return false
+		default:
+		}
+
+		atomic.AddUint64(&r.attempts, 1)
+
+		err := bind()
+		if err == nil {
+			return true
+		}
+		failed++
+
+		if r.onError != nil {
+			r.onError(err)
+		}
+
+		delay, ok := r.policy.Next(failed)
+		if !ok {
+			if r.onGiveUp != nil {
+				r.onGiveUp(failed, err)
+			}
+			return false
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.closed:
+			timer.Stop()
+			return false
+
+		case <-timer.C:
+		}
+	}
+}
+
+// total returns total number of rebinding attempts.
+func (r *rebinder) total() uint64 {
+	return atomic.LoadUint64(&r.attempts)
+}
+
+// close stops rebinding. Must be called once.
+func (r *rebinder) close() {
+	close(r.closed)
+}
@@ -0,0 +1,108 @@
+package gosmpp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("constant", func(t *testing.T) {
+		b := ConstantBackoff{Interval: time.Second, MaxAttempts: 2}
+
+		delay, ok := b.Next(1)
+		require.True(t, ok)
+		require.Equal(t, time.Second, delay)
+
+		_, ok = b.Next(2)
+		require.False(t, ok)
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		b := ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second}
+
+		for failed, expected := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+			delay, ok := b.Next(failed + 1)
+			require.True(t, ok)
+			require.Equal(t, expected*time.Millisecond, delay)
+		}
+
+		b.MaxAttempts = 3
+		_, ok := b.Next(3)
+		require.False(t, ok)
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		b := ExponentialBackoff{Initial: time.Second, Jitter: 0.5}
+
+		for i := 0; i < 100; i++ {
+			delay, ok := b.Next(2)
+			require.True(t, ok)
+			require.True(t, delay >= time.Second && delay <= 3*time.Second)
+		}
+	})
+}
+
+func TestSessionGiveUp(t *testing.T) {
+	var (
+		dialed  int32
+		current atomic.Value // net.Conn
+	)
+
+	// only the first dialing succeeds
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		if atomic.AddInt32(&dialed, 1) > 1 {
+			return nil, fmt.Errorf("SMSC is down")
+		}
+
+		conn, err := NonTLSDialer(ctx, addr)
+		if err == nil {
+			current.Store(conn)
+		}
+		return conn, err
+	}
+
+	var (
+		rebindingErrors int32
+		gaveUp          = make(chan int, 1)
+	)
+
+	session, err := NewTransceiverSession(dialer, nextAuth(), TransceiveSettings{
+		EnquireLink: 200 * time.Millisecond,
+
+		OnRebindingError: func(err error) {
+			atomic.AddInt32(&rebindingErrors, 1)
+		},
+
+		RebindingBackoff: ExponentialBackoff{
+			Initial:     10 * time.Millisecond,
+			MaxAttempts: 3,
+		},
+
+		OnRebindingGaveUp: func(attempts int, err error) {
+			gaveUp <- attempts
+		},
+	}, 0)
+	require.Nil(t, err)
+	defer func() {
+		_ = session.Close()
+	}()
+
+	// connection failure triggers rebinding
+	_ = current.Load().(net.Conn).Close()
+
+	select {
+	case attempts := <-gaveUp:
+		require.Equal(t, 3, attempts)
+		require.EqualValues(t, 3, atomic.LoadInt32(&rebindingErrors))
+		require.EqualValues(t, 3, session.RebindAttempts())
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("Session did not give up rebinding")
+	}
+}
@@ -1,30 +1,189 @@
 package gosmpp
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net"
+	"time"
 
 	"github.com/linxGnu/gosmpp/pdu"
 )
 
-// Dialer is connection dialer.
-type Dialer func(addr string) (net.Conn, error)
+const (
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultBindTimeout         = 30 * time.Second
+)
+
+// Dialer is connection dialer. Dialing must be canceled once context is done.
+type Dialer func(ctx context.Context, addr string) (net.Conn, error)
 
 var (
 	// NonTLSDialer is non-tls connection dialer.
-	NonTLSDialer = func(addr string) (net.Conn, error) {
-		return net.Dial("tcp", addr)
+	NonTLSDialer = func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", addr)
 	}
+
+	// ErrNoEndpoint indicates there is no SMSC address to connect.
+	ErrNoEndpoint = fmt.Errorf("No SMSC address to connect")
 )
 
+// TLSOption configures TLSDialer.
+type TLSOption func(*tlsDialer)
+
+// WithClientCertificate presents certificate(s) to SMSC requiring client authentication.
+func WithClientCertificate(certs ...tls.Certificate) TLSOption {
+	return func(d *tlsDialer) {
+		d.config.Certificates = append(d.config.Certificates, certs...)
+	}
+}
+
+// WithRootCAs pins CA pool which is used to verify SMSC certificate,
+// instead of host's root CA set.
+func WithRootCAs(pool *x509.CertPool) TLSOption {
+	return func(d *tlsDialer) {
+		d.config.RootCAs = pool
+	}
+}
+
+// WithServerName sets server name (SNI) sent to SMSC and verified against its certificate.
+//
+// Default: host part of dialed address.
+func WithServerName(name string) TLSOption {
+	return func(d *tlsDialer) {
+		d.config.ServerName = name
+	}
+}
+
+// WithHandshakeTimeout sets maximum duration for connecting and TLS handshaking.
+// Zero duration means no timeout.
+//
+// Default: 10 secs
+func WithHandshakeTimeout(timeout time.Duration) TLSOption {
+	return func(d *tlsDialer) {
+		d.handshakeTimeout = timeout
+	}
+}
+
+type tlsDialer struct {
+	config           *tls.Config
+	handshakeTimeout time.Duration
+}
+
+// TLSDialer returns tls connection dialer, e.g. for SMPP over TLS on port 3550.
+// Config is cloned, nil config is treated as default one.
+func TLSDialer(config *tls.Config, opts ...TLSOption) Dialer {
+	d := &tlsDialer{
+		handshakeTimeout: defaultTLSHandshakeTimeout,
+	}
+
+	if config != nil {
+		d.config = config.Clone()
+	} else {
+		d.config = &tls.Config{}
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d.dial
+}
+
+func (d *tlsDialer) dial(ctx context.Context, addr string) (conn net.Conn, err error) {
+	if d.handshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.handshakeTimeout)
+		defer cancel()
+	}
+
+	config := d.config
+	if config.ServerName == "" {
+		var host string
+		if host, _, err = net.SplitHostPort(addr); err != nil {
+			return
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	var dialer net.Dialer
+	raw, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return
+	}
+
+	tlsConn := tls.Client(raw, config)
+	if err = withContext(ctx, raw, tlsConn.Handshake); err != nil {
+		_ = raw.Close()
+		return
+	}
+
+	return tlsConn, nil
+}
+
+// EndpointError indicates dialing or binding to SMSC address failed.
+// When all SMSC addresses fail, error of the last tried one is returned.
+type EndpointError struct {
+	Endpoint string
+	Err      error
+}
+
+// Error implements error interface.
+func (e *EndpointError) Error() string {
+	return fmt.Sprintf("SMSC [%s]: %v", e.Endpoint, e.Err)
+}
+
+// Unwrap returns underlying error.
+func (e *EndpointError) Unwrap() error {
+	return e.Err
+}
+
 // Auth represents basic authentication to SMSC.
 type Auth struct {
 	// SMSC represents SMSC address.
 	SMSC string
 
+	// Failover lists secondary SMSC addresses, tried in order after SMSC
+	// when dialing or binding fails.
+	Failover []string
+
+	// Resolver returns ordered SMSC addresses, overriding SMSC and Failover.
+	// It is called before every binding.
+	Resolver func() []string
+
+	// FailbackInterval is interval for session, which is bound to a secondary address,
+	// to try binding preferred addresses again. Requests in flight on the secondary
+	// connection are failed when session fails back.
+	//
+	// Zero duration disables failback.
+	FailbackInterval time.Duration
+
 	// authentication infos
 	SystemID   string
 	Password   string
 	SystemType string
+
+	// BindTimeout is maximum duration for dialing each SMSC address and waiting for its bind_resp.
+	//
+	// Default: 30 secs
+	BindTimeout time.Duration
+
+	// SequenceGenerator generates sequence numbers for requests sent through connections bound with this Auth.
+	// Connections share the generator, hence numbers are unique across rebinds.
+	//
+	// Default: each connection numbers its requests from 1.
+	SequenceGenerator pdu.SequenceGenerator
+}
+
+// endpoints returns ordered SMSC addresses.
+func (s *Auth) endpoints() []string {
+	if s.Resolver != nil {
+		return s.Resolver()
+	}
+	return append([]string{s.SMSC}, s.Failover...)
 }
 
 func newBindRequest(s Auth, bindingType pdu.BindingType) (bindReq *pdu.BindRequest) {
@@ -37,18 +196,36 @@ func newBindRequest(s Auth, bindingType pdu.BindingType) (bindReq *pdu.BindReque
 
 // ConnectAsReceiver connects to SMSC as Receiver.
 func ConnectAsReceiver(dialer Dialer, s Auth) (conn *Connection, err error) {
-	conn, err = connect(dialer, s.SMSC, newBindRequest(s, pdu.Receiver))
-	return
+	return ConnectAsReceiverContext(context.Background(), dialer, s)
 }
 
 // ConnectAsTransmitter connects to SMSC as Transmitter.
 func ConnectAsTransmitter(dialer Dialer, s Auth) (conn *Connection, err error) {
-	conn, err = connect(dialer, s.SMSC, newBindRequest(s, pdu.Transmitter))
-	return
+	return ConnectAsTransmitterContext(context.Background(), dialer, s)
 }
 
 // ConnectAsTransceiver connects to SMSC as Transceiver.
 func ConnectAsTransceiver(dialer Dialer, s Auth) (conn *Connection, err error) {
-	conn, err = connect(dialer, s.SMSC, newBindRequest(s, pdu.Transceiver))
+	return ConnectAsTransceiverContext(context.Background(), dialer, s)
+}
+
+// ConnectAsReceiverContext connects to SMSC as Receiver.
+// Dialing and binding are canceled once context is done.
+func ConnectAsReceiverContext(ctx context.Context, dialer Dialer, s Auth) (conn *Connection, err error) {
+	conn, err = connect(ctx, dialer, s, newBindRequest(s, pdu.Receiver), nil)
+	return
+}
+
+// ConnectAsTransmitterContext connects to SMSC as Transmitter.
+// Dialing and binding are canceled once context is done.
+func ConnectAsTransmitterContext(ctx context.Context, dialer Dialer, s Auth) (conn *Connection, err error) {
+	conn, err = connect(ctx, dialer, s, newBindRequest(s, pdu.Transmitter), nil)
+	return
+}
+
+// ConnectAsTransceiverContext connects to SMSC as Transceiver.
+// Dialing and binding are canceled once context is done.
+func ConnectAsTransceiverContext(ctx context.Context, dialer Dialer, s Auth) (conn *Connection, err error) {
+	conn, err = connect(ctx, dialer, s, newBindRequest(s, pdu.Transceiver), nil)
 	return
 }
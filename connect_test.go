@@ -1,8 +1,27 @@
 package gosmpp
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	smppErrors "github.com/linxGnu/gosmpp/errors"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -15,14 +34,36 @@ var auths = [][2]string{
 }
 
 const (
-	smscAddr = "smscsim.melroselabs.com:2775"
-	mess     = "Thử nghiệm: chuẩn bị nế mễ"
+	smscSystemID = "MelroseLabsSMSC"
+	mess         = "Thử nghiệm: chuẩn bị nế mễ"
 )
 
+var smsc *smpptest.SMSC
+
+func TestMain(m *testing.M) {
+	accounts := make(map[string]string)
+	for _, pair := range auths {
+		accounts[pair[0]] = pair[1]
+	}
+
+	var err error
+	if smsc, err = smpptest.NewSMSC(smpptest.Settings{
+		SystemID:         smscSystemID,
+		Accounts:         accounts,
+		DeliveryReceipts: true,
+	}); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	_ = smsc.Close()
+	os.Exit(code)
+}
+
 func nextAuth() Auth {
 	pair := int(atomic.AddInt32(&currentAuth, 1)) % len(auths)
 	return Auth{
-		SMSC:       smscAddr,
+		SMSC:       smsc.Addr(),
 		SystemID:   auths[pair][0],
 		Password:   auths[pair][1],
 		SystemType: "",
@@ -47,4 +88,190 @@ func TestBindingSMSC(t *testing.T) {
 	require.Nil(t, err)
 	require.NotNil(t, connection)
 	_ = connection.Close()
+
+	// invalid password
+	auth := nextAuth()
+	auth.Password = "wrong"
+	_, err = ConnectAsTransceiver(NonTLSDialer, auth)
+	require.True(t, errors.Is(err, &smppErrors.StatusError{Status: data.ESME_RINVPASWD}))
+
+	var statusErr *smppErrors.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.True(t, statusErr.IsAuthFailure())
+	require.Equal(t, data.BIND_TRANSCEIVER_RESP, statusErr.CommandID)
+
+	// invalid system id
+	auth.SystemID = "unknown"
+	_, err = ConnectAsReceiver(NonTLSDialer, auth)
+	require.True(t, errors.Is(err, &smppErrors.StatusError{Status: data.ESME_RINVSYSID, CommandID: data.BIND_RECEIVER_RESP}))
+
+	// network error is not a status error
+	auth = nextAuth()
+	auth.SMSC = deadAddr(t)
+	_, err = ConnectAsTransmitter(NonTLSDialer, auth)
+	require.NotNil(t, err)
+	require.False(t, errors.As(err, &statusErr))
+}
+
+func TestConnectContext(t *testing.T) {
+	// silent SMSC accepts connections but never responds
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer func() {
+		_ = l.Close()
+	}()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			// drain until client gives up
+			go func() {
+				_, _ = io.Copy(ioutil.Discard, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	auth := nextAuth()
+	auth.SMSC = l.Addr().String()
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := ConnectAsTransceiverContext(ctx, NonTLSDialer, auth)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		_, err := ConnectAsReceiverContext(ctx, NonTLSDialer, auth)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("bindTimeout", func(t *testing.T) {
+		auth := auth
+		auth.BindTimeout = 100 * time.Millisecond
+
+		_, err := ConnectAsTransmitter(NonTLSDialer, auth)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("tlsHandshake", func(t *testing.T) {
+		_, err := ConnectAsTransmitter(TLSDialer(nil, WithHandshakeTimeout(100*time.Millisecond)), auth)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("session", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		session, err := NewTransceiverSessionContext(ctx, NonTLSDialer, auth, TransceiveSettings{}, time.Second)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Nil(t, session)
+
+		_, err = NewPoolContext(ctx, NonTLSDialer, auth, pdu.Transmitter, PoolSettings{Size: 2})
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		connection, err := ConnectAsTransceiverContext(ctx, NonTLSDialer, nextAuth())
+		require.Nil(t, err)
+		_ = connection.Close()
+	})
+}
+
+// selfSignedCert returns certificate for localhost, which is also its own CA.
+func selfSignedCert(t *testing.T) (cert tls.Certificate, pool *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+
+	pool = x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	cert = tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+	return
+}
+
+func TestTLSDialer(t *testing.T) {
+	cert, pool := selfSignedCert(t)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	require.Nil(t, err)
+	go func() {
+		_ = smsc.Server().Serve(l)
+	}()
+
+	auth := nextAuth()
+	auth.SMSC = l.Addr().String()
+
+	t.Run("valid", func(t *testing.T) {
+		dialer := TLSDialer(nil,
+			WithRootCAs(pool),
+			WithClientCertificate(cert),
+			WithServerName("localhost"),
+			WithHandshakeTimeout(time.Second),
+		)
+
+		connection, err := ConnectAsTransceiver(dialer, auth)
+		require.Nil(t, err)
+		defer func() {
+			_ = connection.Close()
+		}()
+
+		state, ok := connection.TLSConnectionState()
+		require.True(t, ok)
+		require.True(t, state.HandshakeComplete)
+		require.Equal(t, "localhost", state.ServerName)
+	})
+
+	t.Run("unknownCA", func(t *testing.T) {
+		_, err := ConnectAsTransceiver(TLSDialer(nil, WithClientCertificate(cert)), auth)
+		require.NotNil(t, err)
+	})
+
+	t.Run("nonTLS", func(t *testing.T) {
+		connection, err := ConnectAsTransmitter(NonTLSDialer, nextAuth())
+		require.Nil(t, err)
+		defer func() {
+			_ = connection.Close()
+		}()
+
+		_, ok := connection.TLSConnectionState()
+		require.False(t, ok)
+	})
 }
@@ -2,15 +2,20 @@ package gosmpp
 
 import (
 	"bufio"
+	"crypto/tls"
 	"net"
 	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
 )
 
 // Connection wraps over net.Conn with buffer reader data reading.
 type Connection struct {
 	systemID string
+	endpoint string
 	conn     net.Conn
 	reader   *bufio.Reader
+	seq      pdu.SequenceGenerator
 }
 
 // NewConnection returns a Connection.
@@ -18,10 +23,28 @@ func NewConnection(conn net.Conn) (c *Connection) {
 	c = &Connection{
 		conn:   conn,
 		reader: bufio.NewReaderSize(conn, 128<<10),
+		seq:    pdu.NewSequenceGenerator(),
 	}
 	return
 }
 
+// SetSequenceGenerator replaces generator of sequence numbers for requests sent through the connection.
+// It must be called before the connection is used.
+func (c *Connection) SetSequenceGenerator(g pdu.SequenceGenerator) {
+	if g != nil {
+		c.seq = g
+	}
+}
+
+// assignSequenceNumber stamps request with next sequence number of the connection.
+// Responses keep sequence numbers of their requests.
+func (c *Connection) assignSequenceNumber(p pdu.PDU) {
+	// most significant bit of command id marks a response
+	if p.GetHeader().CommandID >= 0 {
+		p.SetSequenceNumber(c.seq.Next())
+	}
+}
+
 // Read reads data from the connection.
 // Read can be made to time out and return an Error with Timeout() == true
 // after a fixed time limit; see SetDeadline and SetReadDeadline.
@@ -44,6 +67,19 @@ func (c *Connection) Close() error {
 	return c.conn.Close()
 }
 
+// Endpoint returns SMSC address which connection is bound to.
+func (c *Connection) Endpoint() string {
+	return c.endpoint
+}
+
+// TLSConnectionState returns negotiated TLS state. Returns false if connection is not over TLS.
+func (c *Connection) TLSConnectionState() (state tls.ConnectionState, ok bool) {
+	if conn, isTLS := c.conn.(*tls.Conn); isTLS {
+		state, ok = conn.ConnectionState(), true
+	}
+	return
+}
+
 // LocalAddr returns the local network address.
 func (c *Connection) LocalAddr() net.Addr {
 	return c.conn.LocalAddr()
@@ -1,15 +1,21 @@
 package gosmpp
 
 import (
+	"context"
 	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/smpptest"
+
 	"github.com/stretchr/testify/require"
 )
 
 func TestConnection(t *testing.T) {
-	conn, err := net.Dial("tcp", "smscsim.melroselabs.com:2775")
+	conn, err := net.Dial("tcp", smsc.Addr())
 	require.Nil(t, err)
 
 	c := NewConnection(conn)
@@ -23,3 +29,97 @@ func TestConnection(t *testing.T) {
 	require.Nil(t, c.SetWriteDeadline(time.Now().Add(5*time.Second)))
 	require.Nil(t, c.SetReadDeadline(time.Now().Add(5*time.Second)))
 }
+
+type fixedSequence struct {
+	v int32
+}
+
+func (s *fixedSequence) Next() int32 {
+	return atomic.AddInt32(&s.v, 10)
+}
+
+func TestConnectionSequenceNumber(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	auth := Auth{SMSC: server.Addr(), SystemID: "seq", Password: "seq"}
+
+	t.Run("perConnection", func(t *testing.T) {
+		server.Reset()
+
+		// built before binding, stamped at submitting
+		early := newSubmitSM(auth.SystemID)
+
+		conn, err := ConnectAsTransmitter(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		trans := NewTransmitter(conn, TransmitSettings{})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+		require.Nil(t, trans.Submit(early))
+
+		received, err := server.Wait(data.SUBMIT_SM, 2, time.Second)
+		require.Nil(t, err)
+
+		// bind request takes the first number
+		require.EqualValues(t, 1, server.ReceivedOf(data.BIND_TRANSMITTER)[0].GetSequenceNumber())
+		require.EqualValues(t, 2, received[0].GetSequenceNumber())
+		require.EqualValues(t, 3, received[1].GetSequenceNumber())
+	})
+
+	t.Run("writeOrder", func(t *testing.T) {
+		server.Reset()
+
+		conn, err := ConnectAsTransmitter(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		trans := NewTransmitter(conn, TransmitSettings{WindowSize: 100})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		// concurrent submits are written in order of their sequence numbers
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+				}
+			}()
+		}
+		wg.Wait()
+
+		received, err := server.Wait(data.SUBMIT_SM, 100, time.Second)
+		require.Nil(t, err)
+		for i := 1; i < len(received); i++ {
+			require.Less(t, received[i-1].GetSequenceNumber(), received[i].GetSequenceNumber())
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		server.Reset()
+
+		auth := auth
+		auth.SequenceGenerator = &fixedSequence{}
+
+		conn, err := ConnectAsTransmitter(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		trans := NewTransmitter(conn, TransmitSettings{})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		resp, err := trans.SubmitAndWait(context.Background(), newSubmitSM(auth.SystemID))
+		require.Nil(t, err)
+		require.EqualValues(t, 20, resp.GetSequenceNumber())
+	})
+}
@@ -9,6 +9,7 @@ import (
 const (
 	SM_CONNID_LEN        = 16
 	SM_MSG_LEN           = 254
+	SM_MSG_PAYLOAD_LEN   = 65535
 	SM_SYSID_LEN         = 16
 	SM_MSGID_LEN         = 64
 	SM_PASS_LEN          = 9
@@ -105,6 +106,7 @@ const (
 	SM_ESME_MAN_USER_ACK_TYPE = 0x10 // Send/Recv Msg contains manual/user acknowledgment
 	SM_CONV_ABORT_TYPE        = 0x18 // Recv Msg contains conversation abort (Korean CDMA)
 	SM_INTMD_DLV_NOTIFY_TYPE  = 0x20 // Recv Msg contains intermediate notification
+	SM_MSG_TYPE_MASK          = 0x3C // Message Type bits
 
 	// GSM Network features
 	SM_NONE_GSM           = 0x00 // No specific features selected
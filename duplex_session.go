@@ -0,0 +1,103 @@
+package gosmpp
+
+import (
+	"context"
+	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+// DuplexSession binds a Transmitter and a Receiver with the same Auth and acts as a single
+// Transceiver, for SMSC(s) which do not allow bind_transceiver.
+//
+// Each half is a session on its own, hence rebinds independently.
+type DuplexSession struct {
+	tx *TransmitterSession
+	rx *ReceiverSession
+}
+
+// NewDuplexSession creates new session, binding Transmitter and Receiver.
+//
+// OnPDU receives PDU(s) of both halves: MO messages and delivery receipts from Receiver,
+// responses from Transmitter. See NewTransceiverSession for `rebindingInterval`.
+func NewDuplexSession(dialer Dialer, auth Auth, settings TransceiveSettings, rebindingInterval time.Duration) (session *DuplexSession, err error) {
+	return NewDuplexSessionContext(context.Background(), dialer, auth, settings, rebindingInterval)
+}
+
+// NewDuplexSessionContext creates new session, binding Transmitter and Receiver, like NewDuplexSession.
+// Dialing and binding of the first connections are canceled once context is done.
+func NewDuplexSessionContext(ctx context.Context, dialer Dialer, auth Auth, settings TransceiveSettings, rebindingInterval time.Duration) (session *DuplexSession, err error) {
+	tx, err := NewTransmitterSessionContext(ctx, dialer, auth, settings.transmit(), rebindingInterval)
+	if err != nil {
+		return
+	}
+
+	rx, err := NewReceiverSessionContext(ctx, dialer, auth, settings.receive(), rebindingInterval)
+	if err != nil {
+		_ = tx.Close()
+		return
+	}
+
+	session = &DuplexSession{
+		tx: tx,
+		rx: rx,
+	}
+	return
+}
+
+// TransmitterSession returns transmitter half.
+func (s *DuplexSession) TransmitterSession() *TransmitterSession {
+	return s.tx
+}
+
+// ReceiverSession returns receiver half.
+func (s *DuplexSession) ReceiverSession() *ReceiverSession {
+	return s.rx
+}
+
+// SystemID returns tagged SystemID, returned from bind_resp from SMSC.
+func (s *DuplexSession) SystemID() string {
+	if t := s.tx.Transmitter(); t != nil {
+		return t.SystemID()
+	}
+	return ""
+}
+
+// Submit a PDU through transmitter half.
+func (s *DuplexSession) Submit(p pdu.PDU) error {
+	if t := s.tx.Transmitter(); t != nil {
+		return t.Submit(p)
+	}
+	return ErrTransmitterClosing
+}
+
+// SubmitAndWait submits a PDU through transmitter half and waits for its response from SMSC.
+func (s *DuplexSession) SubmitAndWait(ctx context.Context, p pdu.PDU) (pdu.PDU, error) {
+	if t := s.tx.Transmitter(); t != nil {
+		return t.SubmitAndWait(ctx, p)
+	}
+	return nil, ErrTransmitterClosing
+}
+
+// SubmitKeyed submits a PDU with client message key through transmitter half. See TransmitterSession.SubmitKeyed.
+func (s *DuplexSession) SubmitKeyed(key string, p pdu.PDU) error {
+	return s.tx.SubmitKeyed(key, p)
+}
+
+// Shutdown both halves gracefully. Transmitter half is drained before Receiver half unbinds.
+func (s *DuplexSession) Shutdown(ctx context.Context) (err error) {
+	err = s.tx.Shutdown(ctx)
+	if e := s.rx.Shutdown(ctx); err == nil {
+		err = e
+	}
+	return
+}
+
+// Close both halves.
+func (s *DuplexSession) Close() (err error) {
+	err = s.tx.Close()
+	if e := s.rx.Close(); err == nil {
+		err = e
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package gosmpp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplexSession(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{DeliveryReceipts: true})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var (
+		_         Transceiver = (*DuplexSession)(nil)
+		responses int32
+		receipts  int32
+	)
+
+	auth := Auth{SMSC: server.Addr(), SystemID: "duplex", Password: "duplex"}
+	session, err := NewDuplexSession(NonTLSDialer, auth, TransceiveSettings{
+		EnquireLink: 200 * time.Millisecond,
+		OnPDU: func(p pdu.PDU, responded bool) {
+			switch pd := p.(type) {
+			case *pdu.SubmitSMResp:
+				atomic.AddInt32(&responses, 1)
+
+			case *pdu.DeliverSM:
+				if pd.IsDeliveryReceipt() && responded {
+					atomic.AddInt32(&receipts, 1)
+				}
+			}
+		},
+	}, 100*time.Millisecond)
+	require.Nil(t, err)
+	defer func() {
+		_ = session.Close()
+	}()
+
+	require.Equal(t, smpptest.DefaultSystemID, session.SystemID())
+	require.Len(t, server.Server().Sessions(), 2)
+
+	// responses and receipts are merged into OnPDU
+	require.Nil(t, session.Submit(newSubmitSM(auth.SystemID)))
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&responses) == 1 && atomic.LoadInt32(&receipts) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// receiver half responds to receipts
+	_, err = server.Wait(data.DELIVER_SM_RESP, 1, time.Second)
+	require.Nil(t, err)
+
+	// receiver half rebinds on its own
+	_ = session.ReceiverSession().Receiver().(*receiver).conn.Close()
+	require.Eventually(t, func() bool {
+		return session.ReceiverSession().RebindAttempts() == 1 && healthy(session.ReceiverSession().session)
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Zero(t, session.TransmitterSession().RebindAttempts())
+
+	resp, err := session.SubmitAndWait(context.Background(), newSubmitSM(auth.SystemID))
+	require.Nil(t, err)
+	require.True(t, resp.IsOk())
+}
@@ -30,6 +30,52 @@ var (
 	// ErrShortMessageLengthTooLarge indicates short message length is too large.
 	ErrShortMessageLengthTooLarge error = &SmppErr{err: fmt.Sprintf("Encoded short message data exceeds size of %d", data.SM_MSG_LEN), serialVersionUID: 78237205927624}
 
+	// ErrMessagePayloadTooLarge indicates message payload length is too large.
+	ErrMessagePayloadTooLarge = fmt.Errorf("Encoded message payload exceeds size of %d", data.SM_MSG_PAYLOAD_LEN)
+
 	// ErrUDHTooLong UDH-L is larger than total length of short message data
 	ErrUDHTooLong = fmt.Errorf("User Data Header is too long for PDU short message")
 )
+
+// StatusError indicates SMSC responded to request with error command status.
+// CommandID and SequenceNumber are of the response.
+//
+// It could be matched with errors.Is against StatusError of the same status,
+// and command id if set:
+//
+//	errors.Is(err, &StatusError{Status: data.ESME_RINVPASWD})
+type StatusError struct {
+	Status         data.CommandStatusType
+	CommandID      data.CommandIDType
+	SequenceNumber int32
+}
+
+// Error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("SMSC responded with error command status: %v [%d]. Response: %v", e.Status, e.Status, e.CommandID)
+}
+
+// Is matches StatusError with the same status and command id. Zero command id of target matches any.
+func (e *StatusError) Is(target error) bool {
+	t, ok := target.(*StatusError)
+	return ok && t.Status == e.Status && (t.CommandID == 0 || t.CommandID == e.CommandID)
+}
+
+// IsTemporary checks if status indicates transient error, which is worth retrying later.
+func (e *StatusError) IsTemporary() bool {
+	switch e.Status {
+	case data.ESME_RTHROTTLED, data.ESME_RMSGQFUL, data.ESME_RSYSERR, data.ESME_RX_T_APPN:
+		return true
+	}
+	return false
+}
+
+// IsAuthFailure checks if status indicates binding is rejected due to invalid credentials.
+func (e *StatusError) IsAuthFailure() bool {
+	return e.Status == data.ESME_RINVPASWD || e.Status == data.ESME_RINVSYSID
+}
+
+// IsThrottle checks if status indicates SMSC throttles requests.
+func (e *StatusError) IsThrottle() bool {
+	return e.Status == data.ESME_RTHROTTLED || e.Status == data.ESME_RMSGQFUL
+}
@@ -1,12 +1,44 @@
 package errors
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/linxGnu/gosmpp/data"
+
 	"github.com/stretchr/testify/require"
 )
 
 func TestErr(t *testing.T) {
 	require.True(t, strings.HasPrefix(ErrInvalidPDU.Error(), "Error happened: ["))
 }
+
+func TestStatusError(t *testing.T) {
+	err := fmt.Errorf("binding: %w", &StatusError{
+		Status:         data.ESME_RINVPASWD,
+		CommandID:      data.BIND_TRANSCEIVER_RESP,
+		SequenceNumber: 1,
+	})
+	require.Contains(t, err.Error(), "ESME_RINVPASWD")
+
+	require.True(t, errors.Is(err, &StatusError{Status: data.ESME_RINVPASWD}))
+	require.True(t, errors.Is(err, &StatusError{Status: data.ESME_RINVPASWD, CommandID: data.BIND_TRANSCEIVER_RESP}))
+	require.False(t, errors.Is(err, &StatusError{Status: data.ESME_RINVPASWD, CommandID: data.BIND_RECEIVER_RESP}))
+	require.False(t, errors.Is(err, &StatusError{Status: data.ESME_RALYBND}))
+
+	var statusErr *StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.True(t, statusErr.IsAuthFailure())
+	require.False(t, statusErr.IsTemporary())
+	require.False(t, statusErr.IsThrottle())
+
+	throttled := &StatusError{Status: data.ESME_RTHROTTLED}
+	require.True(t, throttled.IsTemporary())
+	require.True(t, throttled.IsThrottle())
+	require.False(t, throttled.IsAuthFailure())
+
+	require.True(t, (&StatusError{Status: data.ESME_RSYSERR}).IsTemporary())
+	require.False(t, (&StatusError{Status: data.ESME_RALYBND}).IsTemporary())
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"strings"
 	"sync"
 	"time"
 
@@ -75,8 +74,20 @@ func sendingAndReceiveSMS(wg *sync.WaitGroup) {
 }
 
 func handlePDU() func(pdu.PDU, bool) {
-	concatenated := map[uint8][]string{}
-	return func(p pdu.PDU, responded bool) {
+	// concatenated messages are reassembled before passed to OnMessage
+	reassembler := gosmpp.NewReassembler(gosmpp.ReassembleSettings{
+		TTL: time.Minute,
+
+		OnMessage: func(m *gosmpp.ReassembledMessage) {
+			log.Printf("Message from %s (%d parts): %s\n", m.SourceAddr.Address(), len(m.Parts), m.Message)
+		},
+
+		OnError: func(p pdu.PDU, err error) {
+			log.Println("Reassembling error:", err)
+		},
+	})
+
+	return reassembler.OnPDU(func(p pdu.PDU, responded bool) {
 		switch pd := p.(type) {
 		case *pdu.SubmitSMResp:
 			fmt.Printf("SubmitSMResp:%+v\n", pd)
@@ -87,33 +98,11 @@ func handlePDU() func(pdu.PDU, bool) {
 		case *pdu.EnquireLinkResp:
 			fmt.Println("EnquireLinkResp Received")
 
-		case *pdu.DataSM:
-			fmt.Printf("DataSM:%+v\n", pd)
-
 		case *pdu.DeliverSM:
+			// delivery receipts are passed through
 			fmt.Printf("DeliverSM:%+v\n", pd)
-			log.Println(pd.Message.GetMessage())
-			// region concatenated sms (sample code)
-			message, err := pd.Message.GetMessage()
-			if err != nil {
-				log.Fatal(err)
-			}
-			totalParts, sequence, reference, found := pd.Message.UDH().GetConcatInfo()
-			if found {
-				if _, ok := concatenated[reference]; !ok {
-					concatenated[reference] = make([]string, totalParts)
-				}
-				concatenated[reference][sequence-1] = message
-			}
-			if !found {
-				log.Println(message)
-			} else if parts, ok := concatenated[reference]; ok && isConcatenatedDone(parts, totalParts) {
-				log.Println(strings.Join(parts, ""))
-				delete(concatenated, reference)
-			}
-			// endregion
 		}
-	}
+	})
 }
 
 func newSubmitSM() *pdu.SubmitSM {
@@ -139,12 +128,3 @@ func newSubmitSM() *pdu.SubmitSM {
 
 	return submitSM
 }
-
-func isConcatenatedDone(parts []string, total byte) bool {
-	for _, part := range parts {
-		if part != "" {
-			total--
-		}
-	}
-	return total == 0
-}
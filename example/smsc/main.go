@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/server"
+)
+
+// Simple SMSC simulator, built with gosmpp/server.
+//
+// Each SubmitSM is responded with a new message id. If delivery receipt is requested,
+// SMSC generates one. If destination address contains system id of ESME, SMSC simulates
+// a MO back to ESME.
+//
+// Authenticator is not set, hence every bind is accepted.
+func main() {
+	s := server.NewServer(server.Settings{
+		SystemID: "gosmpp-smsc",
+
+		OnPDU: handlePDU,
+
+		OnBound: func(sess *server.Session) {
+			log.Printf("Bound: %s (%d) from %s\n", sess.SystemID(), sess.BindingType(), sess.RemoteAddr())
+		},
+
+		OnClosed: func(sess *server.Session) {
+			log.Printf("Closed: %s from %s\n", sess.SystemID(), sess.RemoteAddr())
+		},
+
+		OnError: func(err error) {
+			log.Println("Error:", err)
+		},
+	})
+
+	log.Fatal(s.ListenAndServe(":2775"))
+}
+
+func handlePDU(sess *server.Session, p pdu.PDU) pdu.PDU {
+	resp := sess.Server().DefaultResponse(p)
+
+	if submitSM, ok := p.(*pdu.SubmitSM); ok {
+		messageID := resp.(*pdu.SubmitSMResp).MessageID
+
+		go func() {
+			// simulate delivery
+			time.Sleep(100 * time.Millisecond)
+
+			if submitSM.RegisteredDelivery&data.SM_SMSC_RECEIPT_MASK != data.SM_SMSC_RECEIPT_NOT_REQUESTED {
+				_ = sess.Server().Deliver(sess.SystemID(), newReceipt(submitSM, messageID))
+			}
+
+			if strings.Contains(submitSM.DestAddr.Address(), sess.SystemID()) {
+				_ = sess.Server().Deliver(sess.SystemID(), newMO(submitSM))
+			}
+		}()
+	}
+
+	return resp
+}
+
+func newMO(submitSM *pdu.SubmitSM) pdu.PDU {
+	deliverSM := pdu.NewDeliverSM().(*pdu.DeliverSM)
+	deliverSM.SourceAddr = submitSM.SourceAddr
+	deliverSM.DestAddr = submitSM.DestAddr
+	deliverSM.Message = submitSM.Message
+	deliverSM.EsmClass = submitSM.EsmClass & data.SM_UDH_GSM
+	return deliverSM
+}
+
+func newReceipt(submitSM *pdu.SubmitSM, messageID string) pdu.PDU {
+	now := time.Now().Format(pdu.ReceiptDateFormat)
+
+	deliverSM := pdu.NewDeliverSM().(*pdu.DeliverSM)
+	deliverSM.SourceAddr = submitSM.DestAddr
+	deliverSM.DestAddr = submitSM.SourceAddr
+	deliverSM.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE
+	_ = deliverSM.Message.SetMessageWithEncoding(fmt.Sprintf("id:%s sub:001 dlvrd:001 submit date:%s done date:%s stat:DELIVRD err:000 text:", messageID, now, now), data.ASCII)
+	return deliverSM
+}
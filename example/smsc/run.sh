@@ -1 +0,0 @@
-g++ smsc.cpp -o smsc && ./smsc
\ No newline at end of file
@@ -0,0 +1,84 @@
+package gosmpp
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultEnquireLinkTimeout   = 5 * time.Second
+	defaultEnquireLinkMaxMissed = 3
+)
+
+// liveness tracks outstanding enquire_link(s) of a connection. Link is dead
+// after a number of consecutive enquire_link(s) are not responded in time.
+type liveness struct {
+	timeout     time.Duration
+	maxMissed   int
+	lock        sync.Mutex
+	outstanding map[int32]time.Time
+	missed      int
+}
+
+func newLiveness(timeout time.Duration, maxMissed int) *liveness {
+	if timeout <= 0 {
+		timeout = defaultEnquireLinkTimeout
+	}
+
+	if maxMissed <= 0 {
+		maxMissed = defaultEnquireLinkMaxMissed
+	}
+
+	return &liveness{
+		timeout:     timeout,
+		maxMissed:   maxMissed,
+		outstanding: make(map[int32]time.Time),
+	}
+}
+
+// sent tracks enquire_link with given sequence number.
+func (l *liveness) sent(sequenceNumber int32, now time.Time) {
+	l.lock.Lock()
+	l.outstanding[sequenceNumber] = now
+	l.lock.Unlock()
+}
+
+// responded marks enquire_link as responded, which proves that link is alive.
+func (l *liveness) responded(sequenceNumber int32) {
+	l.lock.Lock()
+	if _, ok := l.outstanding[sequenceNumber]; ok {
+		delete(l.outstanding, sequenceNumber)
+		l.missed = 0
+	}
+	l.lock.Unlock()
+}
+
+// waiting checks if there is any enquire_link waiting for response.
+func (l *liveness) waiting() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.outstanding) > 0
+}
+
+// missing checks if the last enquire_link is missed, while none is waiting for response.
+func (l *liveness) missing() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.missed > 0 && len(l.outstanding) == 0
+}
+
+// dead counts enquire_link(s) which are not responded in time as missed
+// and checks if link is dead.
+func (l *liveness) dead(now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for sequenceNumber, sent := range l.outstanding {
+		if now.Sub(sent) >= l.timeout {
+			delete(l.outstanding, sequenceNumber)
+			l.missed++
+		}
+	}
+
+	return l.missed >= l.maxMissed
+}
@@ -0,0 +1,205 @@
+package gosmpp
+
+import (
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// silentSMSC accepts binds, then never responds anything.
+func silentSMSC(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer func() {
+					_ = conn.Close()
+				}()
+
+				p, err := pdu.Parse(conn)
+				if err != nil {
+					return
+				}
+
+				if _, err = conn.Write(marshal(p.GetResponse())); err == nil {
+					_, _ = ioutil.ReadAll(conn)
+				}
+			}()
+		}
+	}()
+
+	return l
+}
+
+func TestLiveness(t *testing.T) {
+	t.Run("tracking", func(t *testing.T) {
+		l := newLiveness(time.Second, 2)
+		now := time.Now()
+
+		l.sent(1, now)
+		require.True(t, l.waiting())
+		require.False(t, l.dead(now.Add(time.Second)))
+		require.False(t, l.waiting())
+		require.True(t, l.missing())
+
+		// response resets missed ones
+		l.sent(2, now)
+		l.responded(2)
+		require.False(t, l.dead(now.Add(time.Hour)))
+
+		l.sent(3, now)
+		l.sent(4, now)
+		require.True(t, l.dead(now.Add(time.Second)))
+	})
+
+	t.Run("quietLink", func(t *testing.T) {
+		server, err := smpptest.NewSMSC(smpptest.Settings{})
+		require.Nil(t, err)
+		defer func() {
+			_ = server.Close()
+		}()
+
+		var closed int32
+		auth := Auth{SMSC: server.Addr(), SystemID: "quiet", Password: "quiet"}
+		receiver, err := NewReceiverSession(NonTLSDialer, auth, ReceiveSettings{
+			IdleTimeout: 50 * time.Millisecond,
+			OnClosed: func(state State) {
+				atomic.AddInt32(&closed, 1)
+			},
+		}, 10*time.Millisecond)
+		require.Nil(t, err)
+		defer func() {
+			_ = receiver.Close()
+		}()
+
+		// idle link is checked instead of being torn down
+		_, err = server.Wait(data.ENQUIRE_LINK, 3, time.Second)
+		require.Nil(t, err)
+		require.Zero(t, atomic.LoadInt32(&closed))
+		require.Zero(t, receiver.RebindAttempts())
+	})
+
+	t.Run("deadLink", func(t *testing.T) {
+		l := silentSMSC(t)
+		defer func() {
+			_ = l.Close()
+		}()
+
+		conn, err := ConnectAsTransceiver(NonTLSDialer, Auth{SMSC: l.Addr().String(), SystemID: "dead", Password: "dead"})
+		require.Nil(t, err)
+
+		var notified int32
+		closed := make(chan State, 1)
+		trans := NewTransceiver(conn, TransceiveSettings{
+			IdleTimeout:          20 * time.Millisecond,
+			EnquireLinkTimeout:   50 * time.Millisecond,
+			EnquireLinkMaxMissed: 2,
+			OnReceivingError: func(err error) {
+				if err == ErrEnquireLinkTimeout {
+					atomic.AddInt32(&notified, 1)
+				}
+			},
+			OnClosed: func(state State) {
+				closed <- state
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		select {
+		case state := <-closed:
+			require.Equal(t, ConnectionIssue, state)
+			require.EqualValues(t, 1, atomic.LoadInt32(&notified))
+
+		case <-time.After(2 * time.Second):
+			t.Fatal("dead link is not detected")
+		}
+	})
+
+	t.Run("detectionTime", func(t *testing.T) {
+		l := silentSMSC(t)
+		defer func() {
+			_ = l.Close()
+		}()
+
+		conn, err := ConnectAsTransceiver(NonTLSDialer, Auth{SMSC: l.Addr().String(), SystemID: "dead", Password: "dead"})
+		require.Nil(t, err)
+
+		closed := make(chan State, 1)
+		start := time.Now()
+		trans := NewTransceiver(conn, TransceiveSettings{
+			IdleTimeout:          200 * time.Millisecond,
+			EnquireLinkTimeout:   50 * time.Millisecond,
+			EnquireLinkMaxMissed: 2,
+			OnClosed: func(state State) {
+				closed <- state
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		// dead link is detected after IdleTimeout + MaxMissed * EnquireLinkTimeout,
+		// rather than after MaxMissed * IdleTimeout
+		select {
+		case state := <-closed:
+			require.Equal(t, ConnectionIssue, state)
+			elapsed := time.Since(start)
+			require.True(t, elapsed < 400*time.Millisecond, elapsed)
+
+		case <-time.After(2 * time.Second):
+			t.Fatal("dead link is not detected")
+		}
+	})
+
+	t.Run("idleWriteTimeout", func(t *testing.T) {
+		// nothing is read from client side, hence enquire_link is stuck writing
+		client, server := net.Pipe()
+		defer func() {
+			_ = server.Close()
+		}()
+
+		closed := make(chan State, 1)
+		trans := NewTransceiver(NewConnection(client), TransceiveSettings{
+			WriteTimeout: 50 * time.Millisecond,
+			IdleTimeout:  20 * time.Millisecond,
+			OnClosed: func(state State) {
+				closed <- state
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		select {
+		case state := <-closed:
+			require.Equal(t, ConnectionIssue, state)
+
+		case <-time.After(2 * time.Second):
+			t.Fatal("idle enquire_link is not bounded by WriteTimeout")
+		}
+	})
+
+	t.Run("defaultIdle", func(t *testing.T) {
+		var settings ReceiveSettings
+		settings.normalize()
+		require.Equal(t, defaultReadTimeout, settings.Timeout)
+		require.Equal(t, defaultIdleTimeout, settings.IdleTimeout)
+	})
+}
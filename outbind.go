@@ -0,0 +1,170 @@
+package gosmpp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrOutbindListenerClosed indicates outbind listener is closed. Can not accept connection from SMSC.
+	ErrOutbindListenerClosed = fmt.Errorf("Outbind listener is closed. Can not accept connection from SMSC")
+
+	// ErrOutbindRejected indicates SMSC sent outbind with invalid system_id or password.
+	ErrOutbindRejected = fmt.Errorf("Outbind is rejected. Invalid system_id or password")
+)
+
+// OutbindListener accepts connections initiated by SMSC. SMSC requests binding with outbind,
+// then ESME binds as Receiver or Transceiver on the same connection.
+type OutbindListener struct {
+	listener net.Listener
+	auth     Auth
+	conns    chan net.Conn
+	closed   chan struct{}
+	state    int32
+}
+
+// NewOutbindListener accepts connections from SMSC on listener.
+//
+// Outbind is validated against SystemID and Password of auth, which are used for binding as well.
+func NewOutbindListener(listener net.Listener, auth Auth) *OutbindListener {
+	l := &OutbindListener{
+		listener: listener,
+		auth:     auth,
+		conns:    make(chan net.Conn),
+		closed:   make(chan struct{}),
+	}
+	go l.loop()
+	return l
+}
+
+// ListenOutbind listens on TCP network address for connections from SMSC.
+func ListenOutbind(addr string, auth Auth) (*OutbindListener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewOutbindListener(listener, auth), nil
+}
+
+// Addr returns listening address, which SMSC should connect to.
+func (l *OutbindListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close listener. Bound connections are not affected.
+func (l *OutbindListener) Close() (err error) {
+	if atomic.CompareAndSwapInt32(&l.state, 0, 1) {
+		close(l.closed)
+		err = l.listener.Close()
+	}
+	return
+}
+
+// Dialer returns Dialer which waits for next connection from SMSC with valid outbind, instead of dialing.
+// Address is ignored. It allows sessions to bind through outbind, rebinding on next outbind:
+//
+//	session, err := NewReceiverSession(listener.Dialer(), auth, settings, time.Second)
+//
+// Note that Auth.BindTimeout bounds waiting for outbind as well.
+func (l *OutbindListener) Dialer() Dialer {
+	return l.outbind
+}
+
+// Accept waits for next connection from SMSC with valid outbind and binds on it.
+// Waiting is canceled once context is done.
+//
+// BindingType should be Receiver or Transceiver.
+func (l *OutbindListener) Accept(ctx context.Context, bindingType pdu.BindingType) (conn *Connection, err error) {
+	c, err := l.outbind(ctx, "")
+	if err != nil {
+		return
+	}
+
+	auth := l.auth
+	auth.SMSC, auth.Failover, auth.Resolver = c.RemoteAddr().String(), nil, nil
+
+	accepted := func(context.Context, string) (net.Conn, error) {
+		return c, nil
+	}
+	return connect(ctx, accepted, auth, newBindRequest(auth, bindingType), nil)
+}
+
+// AcceptReceiver waits for next connection from SMSC with valid outbind and binds as Receiver on it.
+func (l *OutbindListener) AcceptReceiver(ctx context.Context, settings ReceiveSettings) (Receiver, error) {
+	conn, err := l.Accept(ctx, pdu.Receiver)
+	if err != nil {
+		return nil, err
+	}
+	return NewReceiver(conn, settings), nil
+}
+
+// outbind waits for next accepted connection and validates its outbind.
+func (l *OutbindListener) outbind(ctx context.Context, _ string) (conn net.Conn, err error) {
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+
+	case c, ok := <-l.conns:
+		if !ok {
+			err = ErrOutbindListenerClosed
+			return
+		}
+		conn = c
+	}
+
+	if err = withContext(ctx, conn, func() error {
+		return l.validate(conn)
+	}); err != nil {
+		_ = conn.Close()
+		conn = nil
+	}
+	return
+}
+
+func (l *OutbindListener) validate(conn net.Conn) (err error) {
+	p, err := parse(conn)
+	if err != nil {
+		return
+	}
+
+	outbind, ok := p.(*pdu.Outbind)
+	if !ok {
+		return fmt.Errorf("Expected outbind but got: %T", p)
+	}
+
+	if outbind.SystemID != l.auth.SystemID || outbind.Password != l.auth.Password {
+		return ErrOutbindRejected
+	}
+
+	return
+}
+
+// loop accepts connections until listener is closed.
+func (l *OutbindListener) loop() {
+	defer close(l.conns)
+
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if nErr, ok := err.(net.Error); ok && nErr.Temporary() && atomic.LoadInt32(&l.state) == 0 {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return
+		}
+
+		select {
+		case l.conns <- conn:
+
+		case <-l.closed:
+			_ = conn.Close()
+			return
+		}
+	}
+}
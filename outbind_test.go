@@ -0,0 +1,108 @@
+package gosmpp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbindListener(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	auth := Auth{SystemID: "outbind", Password: "outbind"}
+
+	listener, err := ListenOutbind("127.0.0.1:0", auth)
+	require.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	addr := listener.Addr().String()
+
+	t.Run("accept", func(t *testing.T) {
+		server.Reset()
+
+		var received int32
+		require.Nil(t, server.Server().Outbind(addr, auth.SystemID, auth.Password))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		receiver, err := listener.AcceptReceiver(ctx, ReceiveSettings{
+			OnPDU: func(p pdu.PDU, responded bool) {
+				if _, ok := p.(*pdu.DeliverSM); ok && responded {
+					atomic.AddInt32(&received, 1)
+				}
+			},
+		})
+		require.Nil(t, err)
+		defer func() {
+			_ = receiver.Close()
+		}()
+		require.Equal(t, smpptest.DefaultSystemID, receiver.SystemID())
+
+		_, err = server.Wait(data.BIND_RECEIVER, 1, time.Second)
+		require.Nil(t, err)
+
+		require.Nil(t, server.Deliver(auth.SystemID, pdu.NewDeliverSM()))
+		_, err = server.Wait(data.DELIVER_SM_RESP, 1, time.Second)
+		require.Nil(t, err)
+		require.EqualValues(t, 1, atomic.LoadInt32(&received))
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		require.Nil(t, server.Server().Outbind(addr, auth.SystemID, "wrong"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := listener.Accept(ctx, pdu.Receiver)
+		require.Equal(t, ErrOutbindRejected, err)
+
+		// waiting is canceled by context
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = listener.Accept(ctx, pdu.Receiver)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("session", func(t *testing.T) {
+		server.Reset()
+
+		go func() {
+			_ = server.Server().Outbind(addr, auth.SystemID, auth.Password)
+		}()
+
+		session, err := NewReceiverSession(listener.Dialer(), auth, ReceiveSettings{}, 10*time.Millisecond)
+		require.Nil(t, err)
+		defer func() {
+			_ = session.Close()
+		}()
+
+		// session rebinds on next outbind
+		require.Nil(t, server.Unbind(auth.SystemID))
+		require.Nil(t, server.Server().Outbind(addr, auth.SystemID, auth.Password))
+
+		_, err = server.Wait(data.BIND_RECEIVER, 2, 2*time.Second)
+		require.Nil(t, err)
+		require.Eventually(t, func() bool {
+			return healthy(session.session)
+		}, time.Second, 10*time.Millisecond)
+		require.NotZero(t, session.RebindAttempts())
+	})
+
+	_ = listener.Close()
+	_, err = listener.Accept(context.Background(), pdu.Receiver)
+	require.Equal(t, ErrOutbindListenerClosed, err)
+}
@@ -0,0 +1,196 @@
+package gosmpp
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrDuplicateKey indicates PDU with the same client message key is stored already.
+	ErrDuplicateKey = fmt.Errorf("PDU with the same key is stored already")
+
+	// ErrNoOutboundStore indicates session is not configured with OutboundStore.
+	ErrNoOutboundStore = fmt.Errorf("Session has no outbound store")
+)
+
+// OutboundPDU is a stored PDU along with its client message key.
+type OutboundPDU struct {
+	Key string
+	PDU pdu.PDU
+}
+
+// OutboundStore holds submitted PDU(s) until SMSC finally responds to them,
+// so that unacknowledged ones could be replayed after rebinding or restarting.
+//
+// Implementation must be safe for concurrent use.
+type OutboundStore interface {
+	// Put stores PDU with client message key.
+	// ErrDuplicateKey is returned if key is stored already.
+	Put(key string, p pdu.PDU) error
+
+	// Ack removes PDU with key, which is finally responded by SMSC.
+	Ack(key string) error
+
+	// Pending returns stored PDU(s), in order of storing.
+	Pending() ([]OutboundPDU, error)
+}
+
+// MemoryStore is in-memory OutboundStore. Stored PDU(s) survive rebinding, but not restarting.
+type MemoryStore struct {
+	lock  sync.Mutex
+	keys  map[string]*list.Element
+	order *list.List // of OutboundPDU, in order of storing
+}
+
+// NewMemoryStore returns new MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		keys:  make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Put implements OutboundStore interface.
+func (s *MemoryStore) Put(key string, p pdu.PDU) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.keys[key]; ok {
+		return ErrDuplicateKey
+	}
+
+	s.keys[key] = s.order.PushBack(OutboundPDU{Key: key, PDU: p})
+	return nil
+}
+
+// Ack implements OutboundStore interface.
+func (s *MemoryStore) Ack(key string) error {
+	s.lock.Lock()
+	if e, ok := s.keys[key]; ok {
+		delete(s.keys, key)
+		s.order.Remove(e)
+	}
+	s.lock.Unlock()
+	return nil
+}
+
+// Pending implements OutboundStore interface.
+func (s *MemoryStore) Pending() ([]OutboundPDU, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pending := make([]OutboundPDU, 0, s.order.Len())
+	for e := s.order.Front(); e != nil; e = e.Next() {
+		pending = append(pending, e.Value.(OutboundPDU))
+	}
+	return pending, nil
+}
+
+// outbound submits keyed PDU(s) through session, acknowledging them in store
+// once they are finally responded.
+type outbound struct {
+	store OutboundStore
+	lock  sync.Mutex
+	sent  map[pdu.PDU]sentPDU // submitted ones, waiting for response
+}
+
+// sentPDU is a keyed PDU along with Transmitter which it is submitted through.
+type sentPDU struct {
+	key string
+	t   Transmitter
+}
+
+func newOutbound(store OutboundStore) *outbound {
+	return &outbound{
+		store: store,
+		sent:  make(map[pdu.PDU]sentPDU),
+	}
+}
+
+// submit stores PDU, then submits it. PDU which is failed to submit stays in store for replaying.
+func (o *outbound) submit(t Transmitter, key string, p pdu.PDU) (err error) {
+	o.lock.Lock()
+	if err = o.store.Put(key, p); err == nil {
+		o.sent[p] = sentPDU{key: key, t: t}
+	}
+	o.lock.Unlock()
+
+	if err == nil {
+		err = o.send(t, p)
+	}
+	return
+}
+
+func (o *outbound) send(t Transmitter, p pdu.PDU) (err error) {
+	if err = t.Submit(p); err != nil {
+		o.lock.Lock()
+		if o.sent[p].t == t {
+			delete(o.sent, p)
+		}
+		o.lock.Unlock()
+	}
+	return
+}
+
+// responded acknowledges request which is finally responded. Request given up without
+// response (nil resp) stays in store, until it is replayed on next binding.
+func (o *outbound) responded(req, resp pdu.PDU) {
+	o.lock.Lock()
+	sent, ok := o.sent[req]
+	delete(o.sent, req)
+	o.lock.Unlock()
+
+	if ok && resp != nil && isFinal(resp) {
+		_ = o.store.Ack(sent.key)
+	}
+}
+
+// replay submits stored PDU(s) through newly bound Transmitter, except ones submitted through it already.
+// PDU(s) in flight on previous binding are replayed as well, thus SMSC might receive them twice.
+func (o *outbound) replay(t Transmitter) {
+	o.lock.Lock()
+	pending, err := o.store.Pending()
+	if err != nil {
+		o.lock.Unlock()
+		return
+	}
+
+	replayed := pending[:0]
+	for _, p := range pending {
+		if o.sent[p.PDU].t != t {
+			o.sent[p.PDU] = sentPDU{key: p.Key, t: t}
+			replayed = append(replayed, p)
+		}
+	}
+	o.lock.Unlock()
+
+	for _, p := range replayed {
+		for {
+			err = o.send(t, p.PDU)
+			if err != ErrWindowFull {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// the rest is replayed on next binding
+		if err == ErrTransmitterClosing {
+			return
+		}
+	}
+}
+
+// isFinal checks if response settles its request. Requests which are rejected
+// temporarily stay in store, until they are replayed on next binding.
+func isFinal(resp pdu.PDU) bool {
+	switch resp.GetHeader().CommandStatus {
+	case data.ESME_RTHROTTLED, data.ESME_RMSGQFUL, data.ESME_RX_T_APPN:
+		return false
+	}
+	return true
+}
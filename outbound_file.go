@@ -0,0 +1,217 @@
+package gosmpp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+const (
+	recordPut byte = 'P'
+	recordAck byte = 'A'
+)
+
+var (
+	// ErrFileStoreClosed indicates file store is closed.
+	ErrFileStoreClosed = fmt.Errorf("File store is closed")
+
+	// ErrKeyTooLong indicates client message key is too long to be stored in file.
+	ErrKeyTooLong = fmt.Errorf("Key is too long, exceeding 65535 bytes")
+)
+
+// FileStore is OutboundStore backed by append-only log file. Stored PDU(s) survive restarting.
+//
+// Each Put and Ack appends a record to the log. Log is compacted on opening, so that
+// only pending PDU(s) are kept. Records are not synced to disk one by one, hence
+// the latest ones might be lost on OS crash.
+type FileStore struct {
+	lock   sync.Mutex
+	mem    *MemoryStore
+	path   string
+	file   *os.File
+	closed bool
+}
+
+// OpenFileStore opens log file at path, creating it if not exists, and restores pending PDU(s) from it.
+func OpenFileStore(path string) (s *FileStore, err error) {
+	mem := NewMemoryStore()
+	if err = restore(path, mem); err != nil {
+		return
+	}
+
+	// rewrite pending PDU(s) only
+	if err = compact(path, mem); err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+
+	s = &FileStore{
+		mem:  mem,
+		path: path,
+		file: file,
+	}
+	return
+}
+
+// Put implements OutboundStore interface.
+func (s *FileStore) Put(key string, p pdu.PDU) (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return ErrFileStoreClosed
+	}
+
+	if len(key) > math.MaxUint16 {
+		return ErrKeyTooLong
+	}
+
+	if err = s.mem.Put(key, p); err == nil {
+		if _, err = s.file.Write(record(recordPut, key, p)); err != nil {
+			_ = s.mem.Ack(key)
+		}
+	}
+	return
+}
+
+// Ack implements OutboundStore interface.
+func (s *FileStore) Ack(key string) (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return ErrFileStoreClosed
+	}
+
+	if _, err = s.file.Write(record(recordAck, key, nil)); err == nil {
+		err = s.mem.Ack(key)
+	}
+	return
+}
+
+// Pending implements OutboundStore interface.
+func (s *FileStore) Pending() ([]OutboundPDU, error) {
+	return s.mem.Pending()
+}
+
+// Close log file.
+func (s *FileStore) Close() (err error) {
+	s.lock.Lock()
+	if !s.closed {
+		s.closed = true
+		err = s.file.Close()
+	}
+	s.lock.Unlock()
+	return
+}
+
+// record encodes log record: op, key length (2 bytes), key, then marshaled PDU for put.
+func record(op byte, key string, p pdu.PDU) []byte {
+	buf := make([]byte, 3, 3+len(key)+64)
+	buf[0] = op
+	binary.BigEndian.PutUint16(buf[1:], uint16(len(key)))
+	buf = append(buf, key...)
+
+	if p != nil {
+		buf = append(buf, marshal(p)...)
+	}
+	return buf
+}
+
+// restore replays log records into store. Truncated record at the end of log,
+// which is left by interrupted write, is ignored.
+func restore(path string, store *MemoryStore) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	r := bufio.NewReader(file)
+	for {
+		var head [3]byte
+		if _, err = io.ReadFull(r, head[:]); err != nil {
+			break
+		}
+
+		key := make([]byte, binary.BigEndian.Uint16(head[1:]))
+		if _, err = io.ReadFull(r, key); err != nil {
+			break
+		}
+
+		switch head[0] {
+		case recordPut:
+			var p pdu.PDU
+			if p, err = parse(r); err != nil {
+				break
+			}
+			_ = store.Put(string(key), p)
+
+		case recordAck:
+			_ = store.Ack(string(key))
+
+		default:
+			err = fmt.Errorf("Invalid outbound log record: %q", head[0])
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	// end of log
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return
+}
+
+// compact rewrites log with pending PDU(s) of store.
+func compact(path string, store *MemoryStore) (err error) {
+	pending, _ := store.Pending()
+
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+
+	w := bufio.NewWriter(file)
+	for _, p := range pending {
+		if _, err = w.Write(record(recordPut, p.Key, p.PDU)); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if e := file.Close(); err == nil {
+		err = e
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, path)
+	} else {
+		_ = os.Remove(tmp)
+	}
+	return
+}
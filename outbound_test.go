@@ -0,0 +1,198 @@
+package gosmpp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func pendingKeys(t *testing.T, store OutboundStore) (keys []string) {
+	pending, err := store.Pending()
+	require.Nil(t, err)
+
+	keys = []string{}
+	for _, p := range pending {
+		keys = append(keys, p.Key)
+	}
+	return
+}
+
+func TestOutboundStore(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		store := NewMemoryStore()
+		require.Nil(t, store.Put("a", newSubmitSM("a")))
+		require.Nil(t, store.Put("b", newSubmitSM("b")))
+		require.Nil(t, store.Put("c", newSubmitSM("c")))
+		require.Equal(t, ErrDuplicateKey, store.Put("a", newSubmitSM("a")))
+
+		require.Nil(t, store.Ack("b"))
+		require.Nil(t, store.Ack("unknown"))
+		require.Equal(t, []string{"a", "c"}, pendingKeys(t, store))
+
+		// acked key could be stored again
+		require.Nil(t, store.Put("b", newSubmitSM("b")))
+		require.Equal(t, []string{"a", "c", "b"}, pendingKeys(t, store))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "outbound")
+		require.Nil(t, err)
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		path := filepath.Join(dir, "outbound.log")
+
+		store, err := OpenFileStore(path)
+		require.Nil(t, err)
+		require.Empty(t, pendingKeys(t, store))
+
+		require.Nil(t, store.Put("a", newSubmitSM("a")))
+		require.Nil(t, store.Put("b", newSubmitSM("b")))
+		require.Equal(t, ErrDuplicateKey, store.Put("a", newSubmitSM("a")))
+		require.Nil(t, store.Ack("a"))
+		require.Nil(t, store.Close())
+		require.Equal(t, ErrFileStoreClosed, store.Put("c", newSubmitSM("c")))
+
+		// interrupted write leaves truncated record
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+		require.Nil(t, err)
+		_, err = f.Write(record(recordPut, "c", newSubmitSM("c"))[:10])
+		require.Nil(t, err)
+		require.Nil(t, f.Close())
+
+		// pending ones are restored after restarting
+		store, err = OpenFileStore(path)
+		require.Nil(t, err)
+		defer func() {
+			_ = store.Close()
+		}()
+		require.Equal(t, []string{"b"}, pendingKeys(t, store))
+
+		pending, err := store.Pending()
+		require.Nil(t, err)
+		submitSM, ok := pending[0].PDU.(*pdu.SubmitSM)
+		require.True(t, ok)
+		require.Equal(t, "b", submitSM.SourceAddr.Address())
+
+		require.Nil(t, store.Put("c", newSubmitSM("c")))
+		require.Equal(t, []string{"b", "c"}, pendingKeys(t, store))
+	})
+}
+
+func TestSubmitKeyed(t *testing.T) {
+	accounts := make(map[string]string)
+	for _, pair := range auths {
+		accounts[pair[0]] = pair[1]
+	}
+
+	smsc, err := smpptest.NewSMSC(smpptest.Settings{Accounts: accounts})
+	require.Nil(t, err)
+	defer func() {
+		_ = smsc.Close()
+	}()
+
+	auth := nextAuth()
+	auth.SMSC = smsc.Addr()
+
+	t.Run("noStore", func(t *testing.T) {
+		session, err := NewTransceiverSession(NonTLSDialer, auth, TransceiveSettings{}, 0)
+		require.Nil(t, err)
+		defer func() {
+			_ = session.Close()
+		}()
+
+		require.Equal(t, ErrNoOutboundStore, session.SubmitKeyed("a", newSubmitSM(auth.SystemID)))
+	})
+
+	t.Run("rebind", func(t *testing.T) {
+		smsc.Reset()
+		store := NewMemoryStore()
+
+		session, err := NewTransceiverSession(NonTLSDialer, auth, TransceiveSettings{
+			OutboundStore: store,
+		}, 50*time.Millisecond)
+		require.Nil(t, err)
+		defer func() {
+			_ = session.Close()
+		}()
+
+		// the first one is responded, the second one is lost along with the link
+		smsc.ReplyNext(smpptest.Reply{}, smpptest.Reply{Drop: true})
+		require.Nil(t, session.SubmitKeyed("a", newSubmitSM(auth.SystemID)))
+		require.Nil(t, session.SubmitKeyed("b", newSubmitSM(auth.SystemID)))
+		require.Equal(t, ErrDuplicateKey, session.SubmitKeyed("b", newSubmitSM(auth.SystemID)))
+
+		_, err = smsc.Wait(data.SUBMIT_SM, 2, time.Second)
+		require.Nil(t, err)
+		require.Eventually(t, func() bool {
+			return len(pendingKeys(t, store)) == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, []string{"b"}, pendingKeys(t, store))
+
+		// unacknowledged one is replayed after rebinding
+		require.Nil(t, smsc.Unbind(auth.SystemID))
+		_, err = smsc.Wait(data.SUBMIT_SM, 3, 2*time.Second)
+		require.Nil(t, err)
+		require.Eventually(t, func() bool {
+			return len(pendingKeys(t, store)) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		smsc.Reset()
+		store := NewMemoryStore()
+
+		session, err := NewTransceiverSession(NonTLSDialer, auth, TransceiveSettings{
+			ResponseTimeout: 100 * time.Millisecond,
+			OutboundStore:   store,
+		}, 0)
+		require.Nil(t, err)
+		defer func() {
+			_ = session.Close()
+		}()
+
+		// not responded in time, it is not tracked anymore but stays in store
+		smsc.ReplyNext(smpptest.Reply{Drop: true})
+		require.Nil(t, session.SubmitKeyed("a", newSubmitSM(auth.SystemID)))
+		require.Eventually(t, func() bool {
+			o := session.outbound
+			o.lock.Lock()
+			defer o.lock.Unlock()
+			return len(o.sent) == 0
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, []string{"a"}, pendingKeys(t, store))
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		smsc.Reset()
+
+		// left by previous process, along with temporarily rejected one
+		store := NewMemoryStore()
+		require.Nil(t, store.Put("a", newSubmitSM(auth.SystemID)))
+		require.Nil(t, store.Put("b", newSubmitSM(auth.SystemID)))
+		smsc.ReplyNext(smpptest.Reply{}, smpptest.Reply{Status: data.ESME_RTHROTTLED})
+
+		session, err := NewTransceiverSession(NonTLSDialer, auth, TransceiveSettings{
+			OutboundStore: store,
+		}, 0)
+		require.Nil(t, err)
+		defer func() {
+			_ = session.Close()
+		}()
+
+		_, err = smsc.Wait(data.SUBMIT_SM, 2, time.Second)
+		require.Nil(t, err)
+		require.Eventually(t, func() bool {
+			return len(pendingKeys(t, store)) == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, []string{"b"}, pendingKeys(t, store))
+	})
+}
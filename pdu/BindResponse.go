@@ -80,7 +80,8 @@ func (c *BindResp) Marshal(b *ByteBuffer) {
 // Unmarshal implements PDU interface.
 func (c *BindResp) Unmarshal(b *ByteBuffer) error {
 	return c.base.unmarshal(b, func(w *ByteBuffer) (err error) {
-		if c.CommandID == data.BIND_TRANSCEIVER_RESP || c.CommandStatus == data.ESME_ROK {
+		// system_id might be omitted when bind is failed
+		if c.CommandID == data.BIND_TRANSCEIVER_RESP || c.CommandStatus == data.ESME_ROK || c.CommandLength > data.PDU_HEADER_SIZE {
 			c.SystemID, err = w.ReadCString()
 		}
 		return
@@ -53,4 +53,15 @@ func TestBindResponse(t *testing.T) {
 			data.BIND_TRANSCEIVER_RESP,
 		)
 	})
+	t.Run("failed", func(t *testing.T) {
+		v := NewBindTransmitterResp().(*BindResp)
+		v.SequenceNumber = 13
+		v.CommandStatus = data.ESME_RINVPASWD
+
+		validate(t,
+			v,
+			"00000011800000020000000e0000000d00",
+			data.BIND_TRANSMITTER_RESP,
+		)
+	})
 }
@@ -31,6 +31,33 @@ func NewDataSM() PDU {
 	return c
 }
 
+// SetMessagePayload sets message with encoding, carried by message_payload TLV.
+func (c *DataSM) SetMessagePayload(message string, enc data.Encoding) (err error) {
+	var sm ShortMessage
+	if err = sm.SetMessagePayloadWithEncoding(message, enc); err == nil {
+		c.DataCoding = sm.DataCoding()
+		c.RegisterOptionalParam(sm.payloadField())
+	}
+	return
+}
+
+// GetMessagePayload returns message carried by message_payload TLV, decoded with data_coding.
+// UDH is skipped if esm_class indicates so.
+func (c *DataSM) GetMessagePayload() (message string, err error) {
+	sm, err := c.PayloadMessage()
+	if err == nil {
+		message, err = sm.GetMessage()
+	}
+	return
+}
+
+// PayloadMessage returns message carried by message_payload TLV, along with its UDH if esm_class indicates so.
+func (c *DataSM) PayloadMessage() (sm ShortMessage, err error) {
+	sm.SetDataCoding(c.DataCoding)
+	err = sm.fromPayload(c.OptionalParameters, c.EsmClass&data.SM_UDH_GSM != 0)
+	return
+}
+
 // CanResponse implements PDU interface.
 func (c *DataSM) CanResponse() bool {
 	return true
@@ -42,6 +42,15 @@ func NewDeliverSM() PDU {
 	return c
 }
 
+// SetMessagePayload sets message with encoding, carried by message_payload TLV instead of short_message
+// (sm_length is zero). Message payload allows up to 64K octets, thus there is no need for segmentation.
+func (c *DeliverSM) SetMessagePayload(message string, enc data.Encoding) (err error) {
+	if err = c.Message.SetMessagePayloadWithEncoding(message, enc); err == nil {
+		c.RegisterOptionalParam(c.Message.payloadField())
+	}
+	return
+}
+
 // CanResponse implements PDU interface.
 func (c *DeliverSM) CanResponse() bool {
 	return true
@@ -54,6 +63,8 @@ func (c *DeliverSM) GetResponse() PDU {
 
 // Marshal implements PDU interface.
 func (c *DeliverSM) Marshal(b *ByteBuffer) {
+	c.Message.syncPayload(c.OptionalParameters)
+
 	c.base.marshal(b, func(b *ByteBuffer) {
 		b.Grow(len(c.ServiceType) + len(c.ScheduleDeliveryTime) + len(c.ValidityPeriod) + 10)
 
@@ -72,8 +83,8 @@ func (c *DeliverSM) Marshal(b *ByteBuffer) {
 }
 
 // Unmarshal implements PDU interface.
-func (c *DeliverSM) Unmarshal(b *ByteBuffer) error {
-	return c.base.unmarshal(b, func(b *ByteBuffer) (err error) {
+func (c *DeliverSM) Unmarshal(b *ByteBuffer) (err error) {
+	if err = c.base.unmarshal(b, func(b *ByteBuffer) (err error) {
 		if c.ServiceType, err = b.ReadCString(); err == nil {
 			if err = c.SourceAddr.Unmarshal(b); err == nil {
 				if err = c.DestAddr.Unmarshal(b); err == nil {
@@ -84,7 +95,7 @@ func (c *DeliverSM) Unmarshal(b *ByteBuffer) error {
 									if c.ValidityPeriod, err = b.ReadCString(); err == nil {
 										if c.RegisteredDelivery, err = b.ReadByte(); err == nil {
 											if c.ReplaceIfPresentFlag, err = b.ReadByte(); err == nil {
-												err = c.Message.Unmarshal(b, c.EsmClass == data.SM_UDH_GSM)
+												err = c.Message.Unmarshal(b, c.EsmClass&data.SM_UDH_GSM != 0)
 											}
 										}
 									}
@@ -96,5 +107,40 @@ func (c *DeliverSM) Unmarshal(b *ByteBuffer) error {
 			}
 		}
 		return
-	})
+	}); err == nil {
+		// message might be carried by message_payload
+		err = c.Message.fromPayload(c.OptionalParameters, c.EsmClass&data.SM_UDH_GSM != 0)
+	}
+	return
+}
+
+// IsDeliveryReceipt checks if esm_class indicates that PDU carries SMSC delivery receipt.
+func (c *DeliverSM) IsDeliveryReceipt() bool {
+	return c.EsmClass&data.SM_MSG_TYPE_MASK == data.SM_SMSC_DLV_RCPT_TYPE
+}
+
+// DeliveryReceipt parses carried delivery receipt with DefaultReceiptTextParser.
+func (c *DeliverSM) DeliveryReceipt() (*DeliveryReceipt, error) {
+	return c.DeliveryReceiptWith(DefaultReceiptTextParser)
+}
+
+// DeliveryReceiptWith parses carried delivery receipt with given text parser.
+func (c *DeliverSM) DeliveryReceiptWith(parser ReceiptTextParser) (*DeliveryReceipt, error) {
+	if !c.IsDeliveryReceipt() {
+		return nil, ErrNotDeliveryReceipt
+	}
+	return ParseDeliveryReceipt(c.receiptText(), c.OptionalParameters, parser)
+}
+
+// receiptText returns text body of receipt. Receipts are commonly ASCII regardless of data_coding,
+// thus only UCS2 text is decoded.
+func (c *DeliverSM) receiptText() string {
+	if c.Message.Encoding() == data.UCS2 {
+		if st, err := c.Message.GetMessage(); err == nil {
+			return st
+		}
+	}
+
+	raw, _ := c.Message.GetMessageData()
+	return string(raw)
 }
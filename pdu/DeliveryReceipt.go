@@ -0,0 +1,193 @@
+package pdu
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+)
+
+var (
+	// ErrNotDeliveryReceipt indicates PDU does not carry delivery receipt.
+	ErrNotDeliveryReceipt = fmt.Errorf("PDU does not carry delivery receipt")
+
+	// ErrInvalidDeliveryReceipt indicates delivery receipt carries neither message id nor message state.
+	ErrInvalidDeliveryReceipt = fmt.Errorf("Delivery receipt is invalid: no message id or message state")
+)
+
+// ReceiptDateFormat is format of submit date and done date in delivery receipt: YYMMDDhhmm.
+const ReceiptDateFormat = "0601021504"
+
+// receiptDateFormatWithSeconds is used by some vendors: YYMMDDhhmmss.
+const receiptDateFormatWithSeconds = "060102150405"
+
+// DeliveryReceipt represents SMSC delivery receipt, carried by deliver_sm.
+type DeliveryReceipt struct {
+	// MessageID is id of the original submitted message.
+	MessageID string
+
+	// Submitted is number of short messages originally submitted.
+	Submitted int
+
+	// Delivered is number of short messages delivered.
+	Delivered int
+
+	// SubmitDate is time the original message was submitted.
+	SubmitDate time.Time
+
+	// DoneDate is time the message reached its final state.
+	DoneDate time.Time
+
+	// Stat is final state of the message as written in receipt text (DELIVRD, UNDELIV, ...).
+	Stat string
+
+	// State is final state of the message, one of data.SM_STATE_* constants. Zero if unknown.
+	State byte
+
+	// Err is network specific error code or SMSC error code.
+	Err string
+
+	// Text is the first characters of the original message.
+	Text string
+
+	// NetworkType is network type of network_error_code TLV.
+	NetworkType byte
+
+	// NetworkErrorCode is error code of network_error_code TLV.
+	NetworkErrorCode uint16
+}
+
+// ReceiptTextParser parses text body of delivery receipt into receipt.
+type ReceiptTextParser func(text string, receipt *DeliveryReceipt) error
+
+// DefaultReceiptTextParser is used by DeliverSM.DeliveryReceipt.
+// It could be replaced to support vendor specific formats.
+var DefaultReceiptTextParser ReceiptTextParser = ParseReceiptText
+
+var receiptFieldPattern = regexp.MustCompile(`(?i)(?:^|\s)(id|sub|dlvrd|submit[ _]date|done[ _]date|stat|err|text)\s*:`)
+
+// ParseReceiptText parses receipt text in common format:
+//
+//	id:IIIIIIIIII sub:SSS dlvrd:DDD submit date:YYMMDDhhmm done date:YYMMDDhhmm stat:DDDDDDD err:E text:...
+//
+// Parsing is tolerant: field names are case-insensitive, fields could be missing or reordered,
+// unparsable numbers and dates are left zero.
+func ParseReceiptText(text string, receipt *DeliveryReceipt) error {
+	matches := receiptFieldPattern.FindAllStringSubmatchIndex(text, -1)
+
+	for i, m := range matches {
+		key := strings.ToLower(strings.Replace(text[m[2]:m[3]], "_", " ", 1))
+
+		end := len(text)
+		if key != "text" && i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		value := strings.TrimSpace(text[m[1]:end])
+
+		switch key {
+		case "id":
+			receipt.MessageID = value
+
+		case "sub":
+			receipt.Submitted, _ = strconv.Atoi(value)
+
+		case "dlvrd":
+			receipt.Delivered, _ = strconv.Atoi(value)
+
+		case "submit date":
+			receipt.SubmitDate = parseReceiptDate(value)
+
+		case "done date":
+			receipt.DoneDate = parseReceiptDate(value)
+
+		case "stat":
+			receipt.Stat = value
+			receipt.State = ReceiptState(value)
+
+		case "err":
+			receipt.Err = value
+
+		case "text":
+			receipt.Text = text[m[1]:]
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func parseReceiptDate(value string) (t time.Time) {
+	switch len(value) {
+	case len(ReceiptDateFormat):
+		t, _ = time.Parse(ReceiptDateFormat, value)
+
+	case len(receiptDateFormatWithSeconds):
+		t, _ = time.Parse(receiptDateFormatWithSeconds, value)
+	}
+	return
+}
+
+// ReceiptState maps stat of receipt text to data.SM_STATE_* constant. Returns zero if stat is unknown.
+func ReceiptState(stat string) byte {
+	switch strings.ToUpper(strings.TrimSpace(stat)) {
+	case "ENROUTE", "EN_ROUTE":
+		return data.SM_STATE_EN_ROUTE
+
+	case "DELIVRD", "DELIVERED":
+		return data.SM_STATE_DELIVERED
+
+	case "EXPIRED":
+		return data.SM_STATE_EXPIRED
+
+	case "DELETED":
+		return data.SM_STATE_DELETED
+
+	case "UNDELIV", "UNDELIVERABLE":
+		return data.SM_STATE_UNDELIVERABLE
+
+	case "ACCEPTD", "ACCEPTED":
+		return data.SM_STATE_ACCEPTED
+
+	case "UNKNOWN", "INVALID":
+		return data.SM_STATE_INVALID
+
+	case "REJECTD", "REJECTED":
+		return data.SM_STATE_REJECTED
+	}
+	return 0
+}
+
+// ParseDeliveryReceipt parses delivery receipt from receipt text and optional params
+// (receipted_message_id, message_state, network_error_code). Optional params take precedence.
+func ParseDeliveryReceipt(text string, params map[Tag]Field, parser ReceiptTextParser) (receipt *DeliveryReceipt, err error) {
+	receipt = &DeliveryReceipt{}
+
+	if parser != nil && text != "" {
+		if err = parser(text, receipt); err != nil {
+			return nil, err
+		}
+	}
+
+	tlv := base{OptionalParameters: params}
+
+	if id, ok := tlv.ReceiptedMessageID(); ok && id != "" {
+		receipt.MessageID = id
+	}
+
+	if state, ok := tlv.MessageState(); ok {
+		receipt.State = state
+	}
+
+	if code, ok := tlv.NetworkErrorCode(); ok {
+		receipt.NetworkType, receipt.NetworkErrorCode = code.NetworkType, code.ErrorCode
+	}
+
+	if receipt.MessageID == "" && receipt.State == 0 {
+		return nil, ErrInvalidDeliveryReceipt
+	}
+
+	return
+}
@@ -0,0 +1,97 @@
+package pdu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryReceipt(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		v := NewDeliverSM().(*DeliverSM)
+		require.False(t, v.IsDeliveryReceipt())
+		_, err := v.DeliveryReceipt()
+		require.Equal(t, ErrNotDeliveryReceipt, err)
+
+		// other message types sharing the receipt bit
+		v.EsmClass = data.SM_INTMD_DLV_NOTIFY_TYPE | data.SM_SMSC_DLV_RCPT_TYPE
+		require.False(t, v.IsDeliveryReceipt())
+		v.EsmClass = data.SM_ESME_MAN_USER_ACK_TYPE | data.SM_SMSC_DLV_RCPT_TYPE
+		require.False(t, v.IsDeliveryReceipt())
+
+		v.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE | data.SM_UDH_GSM
+		require.True(t, v.IsDeliveryReceipt())
+
+		v.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE
+		_ = v.Message.SetMessageWithEncoding("id:0123456789 sub:001 dlvrd:001 submit date:2010151230 done date:2010151231 stat:DELIVRD err:000 text:Hello id:world", data.ASCII)
+		require.True(t, v.IsDeliveryReceipt())
+
+		// marshal and parse back
+		buf := NewBuffer(nil)
+		v.Marshal(buf)
+		p, err := Parse(buf)
+		require.Nil(t, err)
+
+		receipt, err := p.(*DeliverSM).DeliveryReceipt()
+		require.Nil(t, err)
+		require.Equal(t, &DeliveryReceipt{
+			MessageID:  "0123456789",
+			Submitted:  1,
+			Delivered:  1,
+			SubmitDate: time.Date(2020, 10, 15, 12, 30, 0, 0, time.UTC),
+			DoneDate:   time.Date(2020, 10, 15, 12, 31, 0, 0, time.UTC),
+			Stat:       "DELIVRD",
+			State:      data.SM_STATE_DELIVERED,
+			Err:        "000",
+			Text:       "Hello id:world",
+		}, receipt)
+	})
+
+	t.Run("tolerant", func(t *testing.T) {
+		var receipt DeliveryReceipt
+		require.Nil(t, ParseReceiptText("ID:abc STAT:undeliv Done_Date:201015123159 sub:xyz Err:0x0B", &receipt))
+		require.Equal(t, DeliveryReceipt{
+			MessageID: "abc",
+			DoneDate:  time.Date(2020, 10, 15, 12, 31, 59, 0, time.UTC),
+			Stat:      "undeliv",
+			State:     data.SM_STATE_UNDELIVERABLE,
+			Err:       "0x0B",
+		}, receipt)
+	})
+
+	t.Run("tlv", func(t *testing.T) {
+		v := NewDeliverSM().(*DeliverSM)
+		v.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE | data.SM_UDH_GSM
+		_ = v.Message.SetMessageWithEncoding("id:text-id stat:DELIVRD", data.UCS2)
+		v.RegisterOptionalParam(Field{Tag: TagReceiptedMessageID, Data: []byte("tlv-id\x00")})
+		v.RegisterOptionalParam(Field{Tag: TagMessageStateOption, Data: []byte{data.SM_STATE_EXPIRED}})
+		v.RegisterOptionalParam(Field{Tag: TagNetworkErrorCode, Data: []byte{3, 0x01, 0x02}})
+
+		receipt, err := v.DeliveryReceipt()
+		require.Nil(t, err)
+		require.Equal(t, "tlv-id", receipt.MessageID)
+		require.Equal(t, "DELIVRD", receipt.Stat)
+		require.EqualValues(t, data.SM_STATE_EXPIRED, receipt.State)
+		require.EqualValues(t, 3, receipt.NetworkType)
+		require.EqualValues(t, 0x0102, receipt.NetworkErrorCode)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		v := NewDeliverSM().(*DeliverSM)
+		v.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE
+		_ = v.Message.SetMessageWithEncoding("msg=42;status=ok", data.ASCII)
+
+		_, err := v.DeliveryReceipt()
+		require.Equal(t, ErrInvalidDeliveryReceipt, err)
+
+		receipt, err := v.DeliveryReceiptWith(func(text string, receipt *DeliveryReceipt) error {
+			receipt.MessageID, receipt.State = "42", data.SM_STATE_DELIVERED
+			return nil
+		})
+		require.Nil(t, err)
+		require.Equal(t, "42", receipt.MessageID)
+	})
+}
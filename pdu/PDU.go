@@ -44,6 +44,12 @@ type PDU interface {
 
 	// SetSequenceNumber manually sets sequence number.
 	SetSequenceNumber(int32)
+
+	// SetCommandStatus sets command status.
+	SetCommandStatus(data.CommandStatusType)
+
+	// ValidateOptionalParams validates optional params against TLV definitions.
+	ValidateOptionalParams() error
 }
 
 type base struct {
@@ -65,6 +71,9 @@ func (c *base) GetHeader() Header {
 func (c *base) unmarshal(b *ByteBuffer, bodyReader func(*ByteBuffer) error) (err error) {
 	fullLen := b.Len()
 
+	// invalid TLV does not break parsing, it is reported after the whole PDU is read
+	var invalid error
+
 	if err = c.Header.Unmarshal(b); err == nil {
 
 		// try to unmarshal body
@@ -89,7 +98,7 @@ func (c *base) unmarshal(b *ByteBuffer, bodyReader func(*ByteBuffer) error) (err
 				// the rest is optional body
 				var optionalBody []byte
 				if optionalBody, err = b.ReadN(cmdLength - got); err == nil {
-					err = c.unmarshalOptionalBody(optionalBody)
+					invalid, err = c.unmarshalOptionalBody(optionalBody)
 				}
 
 				if err != nil {
@@ -100,6 +109,8 @@ func (c *base) unmarshal(b *ByteBuffer, bodyReader func(*ByteBuffer) error) (err
 			// validate again
 			if b.Len() != fullLen-cmdLength {
 				err = errors.ErrInvalidPDU
+			} else {
+				err = invalid
 			}
 		}
 	}
@@ -107,12 +118,17 @@ func (c *base) unmarshal(b *ByteBuffer, bodyReader func(*ByteBuffer) error) (err
 	return
 }
 
-func (c *base) unmarshalOptionalBody(body []byte) (err error) {
+// unmarshalOptionalBody reads all TLVs. The first TLV which violates its definition
+// is returned as invalid. C-string value without null terminator is accepted.
+func (c *base) unmarshalOptionalBody(body []byte) (invalid, err error) {
 	buf := NewBuffer(body)
 	for buf.Len() > 0 {
 		var field Field
 		if err = field.Unmarshal(buf); err == nil {
 			c.OptionalParameters[field.Tag] = field
+			if e := field.validate(true); e != nil && invalid == nil {
+				invalid = e
+			}
 		} else {
 			return
 		}
@@ -157,7 +173,19 @@ func (c *base) IsGNack() bool {
 	return c.CommandID == data.GENERIC_NACK
 }
 
+// ResponseError returns *errors.StatusError if PDU is response with error command status, nil otherwise.
+func ResponseError(p PDU) error {
+	h := p.GetHeader()
+	if h.CommandID >= 0 || h.CommandStatus == data.ESME_ROK {
+		return nil
+	}
+	return &errors.StatusError{Status: h.CommandStatus, CommandID: h.CommandID, SequenceNumber: h.SequenceNumber}
+}
+
 // Parse PDU from reader.
+//
+// Well-framed PDU with unknown command id is returned as UnknownPDU, along with errors.ErrUnknownCommandID.
+// PDU carrying TLV which violates its definition is returned as well, along with *InvalidTLVError.
 func Parse(r io.Reader) (pdu PDU, err error) {
 	var headerBytes [16]byte
 
@@ -180,13 +208,21 @@ func Parse(r io.Reader) (pdu PDU, err error) {
 	}
 
 	// try to create pdu
-	if pdu, err = CreatePDUFromCmdID(header.CommandID); err == nil {
-		buf := NewBuffer(make([]byte, 0, header.CommandLength))
-		_, _ = buf.Write(headerBytes[:])
-		if len(bodyBytes) > 0 {
-			_, _ = buf.Write(bodyBytes)
-		}
-		err = pdu.Unmarshal(buf)
+	unknown := false
+	if pdu, err = CreatePDUFromCmdID(header.CommandID); err == errors.ErrUnknownCommandID {
+		pdu, unknown = &UnknownPDU{base: newBase()}, true
+	} else if err != nil {
+		return
+	}
+
+	buf := NewBuffer(make([]byte, 0, header.CommandLength))
+	_, _ = buf.Write(headerBytes[:])
+	if len(bodyBytes) > 0 {
+		_, _ = buf.Write(bodyBytes)
+	}
+
+	if err = pdu.Unmarshal(buf); err == nil && unknown {
+		err = errors.ErrUnknownCommandID
 	}
 
 	return
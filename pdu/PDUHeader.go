@@ -54,7 +54,9 @@ func (c *Header) Unmarshal(b *ByteBuffer) (err error) {
 
 var sequenceNumber int32
 
-// AssignSequenceNumber assigns sequence number auto-incrementally.
+// AssignSequenceNumber assigns sequence number auto-incrementally, from a counter shared by the whole process.
+//
+// Requests sent through a connection are stamped again with its own SequenceGenerator.
 func (c *Header) AssignSequenceNumber() {
 	c.SetSequenceNumber(nextSequenceNumber(&sequenceNumber))
 }
@@ -74,6 +76,11 @@ func (c *Header) SetSequenceNumber(v int32) {
 	c.SequenceNumber = v
 }
 
+// SetCommandStatus sets command status.
+func (c *Header) SetCommandStatus(v data.CommandStatusType) {
+	c.CommandStatus = v
+}
+
 // Marshal to buffer.
 func (c *Header) Marshal(b *ByteBuffer) {
 	b.Grow(16)
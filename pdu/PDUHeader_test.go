@@ -11,3 +11,12 @@ func TestNextSeq(t *testing.T) {
 	var v int32 = math.MaxInt32
 	require.EqualValues(t, 1, nextSequenceNumber(&v))
 }
+
+func TestSequenceGenerator(t *testing.T) {
+	g1, g2 := NewSequenceGenerator(), NewSequenceGenerator()
+	require.EqualValues(t, 1, g1.Next())
+	require.EqualValues(t, 2, g1.Next())
+
+	// generators are independent
+	require.EqualValues(t, 1, g2.Next())
+}
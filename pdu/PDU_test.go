@@ -1,8 +1,10 @@
 package pdu
 
 import (
+	stdErrors "errors"
 	"testing"
 
+	"github.com/linxGnu/gosmpp/data"
 	"github.com/linxGnu/gosmpp/errors"
 
 	"github.com/stretchr/testify/require"
@@ -51,3 +53,24 @@ func TestParsePDU(t *testing.T) {
 		}))
 	})
 }
+
+func TestResponseError(t *testing.T) {
+	req := NewSubmitSM()
+	req.SetSequenceNumber(7)
+	resp := req.GetResponse()
+	require.Nil(t, ResponseError(resp))
+
+	// requests carry no status
+	req.SetCommandStatus(data.ESME_RSYSERR)
+	require.Nil(t, ResponseError(req))
+
+	resp.SetCommandStatus(data.ESME_RTHROTTLED)
+	err := ResponseError(resp)
+
+	var statusErr *errors.StatusError
+	require.True(t, stdErrors.As(err, &statusErr))
+	require.Equal(t, data.ESME_RTHROTTLED, statusErr.Status)
+	require.Equal(t, data.SUBMIT_SM_RESP, statusErr.CommandID)
+	require.EqualValues(t, 7, statusErr.SequenceNumber)
+	require.True(t, statusErr.IsThrottle())
+}
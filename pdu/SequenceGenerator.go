@@ -0,0 +1,24 @@
+package pdu
+
+// SequenceGenerator generates sequence numbers for PDU(s) sent through a connection.
+//
+// Custom implementation could be persistent or unique across a cluster.
+// It must be safe for concurrent use.
+type SequenceGenerator interface {
+	// Next returns next sequence number, in range of 0x01 to 0x7FFFFFFF.
+	Next() int32
+}
+
+type sequenceCounter struct {
+	v int32
+}
+
+// NewSequenceGenerator returns in-memory generator, which increments from 1.
+func NewSequenceGenerator() SequenceGenerator {
+	return &sequenceCounter{}
+}
+
+// Next returns next sequence number.
+func (c *sequenceCounter) Next() int32 {
+	return nextSequenceNumber(&c.v)
+}
@@ -20,6 +20,7 @@ type ShortMessage struct {
 	udHeader          UDH
 	messageData       []byte
 	withoutDataCoding bool
+	viaPayload        bool
 }
 
 // NewShortMessage returns new ShortMessage.
@@ -70,17 +71,82 @@ func (c *ShortMessage) SetMessageWithEncoding(message string, enc data.Encoding)
 			c.message = message
 			c.enc = enc
 			c.dataCoding = enc.DataCoding()
+			c.viaPayload = false
 		}
 	}
 	return
 }
 
+// SetMessagePayloadWithEncoding set message with encoding, to be carried by message_payload TLV
+// instead of short_message field, which allows up to 64K octets.
+//
+// It's preferred to use SetMessagePayload of PDU (SubmitSM, DeliverSM), which also registers the TLV.
+func (c *ShortMessage) SetMessagePayloadWithEncoding(message string, enc data.Encoding) (err error) {
+	var payload []byte
+	if payload, err = enc.Encode(message); err == nil {
+		if len(payload) > data.SM_MSG_PAYLOAD_LEN {
+			err = errors.ErrMessagePayloadTooLarge
+		} else {
+			c.message = message
+			c.enc = enc
+			c.dataCoding = enc.DataCoding()
+			c.messageData = payload
+			c.udHeader = nil
+			c.viaPayload = true
+		}
+	}
+	return
+}
+
+// IsPayload returns true if message is carried by message_payload TLV, thus sm_length is zero.
+func (c *ShortMessage) IsPayload() bool {
+	return c.viaPayload
+}
+
+// payloadField returns message_payload TLV carrying the message.
+func (c *ShortMessage) payloadField() Field {
+	return Field{Tag: TagMessagePayload, Data: c.messageData}
+}
+
+// syncPayload keeps message_payload TLV of PDU in line with message. Since short_message and
+// message_payload are mutually exclusive, stale TLV is dropped once short message is set.
+func (c *ShortMessage) syncPayload(params map[Tag]Field) {
+	if !c.viaPayload && len(c.messageData) > 0 {
+		delete(params, TagMessagePayload)
+	}
+}
+
+// fromPayload loads message from message_payload TLV when short_message is empty (sm_length is zero).
+func (c *ShortMessage) fromPayload(params map[Tag]Field, udhi bool) (err error) {
+	if len(c.messageData) > 0 {
+		return
+	}
+
+	f, ok := params[TagMessagePayload]
+	if !ok || len(f.Data) == 0 {
+		return
+	}
+
+	c.messageData = f.Data
+	c.viaPayload = true
+
+	if udhi {
+		udh := UDH{}
+		if _, err = udh.UnmarshalBinary(c.messageData); err == nil {
+			c.udHeader = udh
+		}
+	}
+
+	return
+}
+
 // SetLongMessageWithEnc set ShortMessage with message longer than  256 bytes
 // callers are expected to call Split() after this
 func (c *ShortMessage) SetLongMessageWithEnc(message string, enc data.Encoding) (err error) {
 	c.message = message
 	c.enc = enc
 	c.dataCoding = enc.DataCoding()
+	c.viaPayload = false
 	return
 }
 
@@ -159,11 +225,60 @@ func (c *ShortMessage) GetMessageWithEncoding(enc data.Encoding) (st string, err
 	return
 }
 
+// SplitMode indicates how concatenation info is carried by segments of long message.
+type SplitMode byte
+
+const (
+	// SplitUDH8Bit carries concatenation info within UDH IE 0x00, with 8-bit reference number.
+	SplitUDH8Bit SplitMode = iota
+
+	// SplitUDH16Bit carries concatenation info within UDH IE 0x08, with 16-bit reference number.
+	SplitUDH16Bit
+
+	// SplitSAR carries concatenation info within sar_msg_ref_num, sar_total_segments
+	// and sar_segment_seqnum TLVs of PDU. Segments have no UDH.
+	SplitSAR
+)
+
+// udhLen returns length of concatenation UDH, including UDHL byte.
+func (m SplitMode) udhLen() int {
+	switch m {
+	case SplitUDH16Bit:
+		return 7
+
+	case SplitSAR:
+		return 0
+
+	default:
+		return 6
+	}
+}
+
+// SplitOptions is options for splitting long message.
+type SplitOptions struct {
+	// Mode of concatenation.
+	//
+	// Default: SplitUDH8Bit
+	Mode SplitMode
+
+	// Reference number shared by all segments. Zero means auto-generated.
+	// Only lower 8 bits are used with SplitUDH8Bit.
+	Reference uint16
+}
+
 // Split split one short message and split into multiple short message, with UDH
 // according to 33GP TS 23.040 section 9.2.3.24.1
 // NOTE: Split() will return array of length 1 if data length is still within the limit
 // The encoding interface can implement the data.Splitter interface for ad-hoc splitting rule
 func (c *ShortMessage) Split() (multiSM []*ShortMessage, err error) {
+	return c.SplitWithOptions(SplitOptions{})
+}
+
+// SplitWithOptions is similar to Split, with options to choose concatenation mode and reference number.
+//
+// With SplitSAR mode, segments have no UDH. Caller is responsible for setting sar_* TLVs,
+// e.g. by using SubmitSM.SplitWithOptions.
+func (c *ShortMessage) SplitWithOptions(opts SplitOptions) (multiSM []*ShortMessage, err error) {
 	var encoding data.Encoding
 	if c.enc == nil {
 		encoding = data.GSM7BIT
@@ -180,15 +295,32 @@ func (c *ShortMessage) Split() (multiSM []*ShortMessage, err error) {
 		return
 	}
 
-	// reserve 6 bytes for concat message UDH
-	segments, err := splitter.EncodeSplit(c.message, data.SM_GSM_MSG_LEN-6)
+	// reserve bytes for concat message UDH
+	segments, err := splitter.EncodeSplit(c.message, uint(data.SM_GSM_MSG_LEN-opts.Mode.udhLen()))
 	if err != nil {
 		return nil, err
 	}
 
-	ref := getRefNum() // all segments will have the same ref id
+	// all segments will have the same ref id
+	ref := opts.Reference
+	if ref == 0 {
+		ref = uint16(getRefNum())
+	}
+
 	multiSM = []*ShortMessage{}
 	for i, seg := range segments {
+		var udh UDH
+		switch opts.Mode {
+		case SplitUDH16Bit:
+			udh = UDH{NewIEConcatMessage16(uint8(len(segments)), uint8(i+1), ref)}
+
+		case SplitSAR:
+			// concatenation info is carried by TLVs of PDU
+
+		default:
+			udh = UDH{NewIEConcatMessage(uint8(len(segments)), uint8(i+1), uint8(ref))}
+		}
+
 		// create new SM, encode data
 		multiSM = append(multiSM, &ShortMessage{
 			enc:        c.enc,
@@ -196,7 +328,7 @@ func (c *ShortMessage) Split() (multiSM []*ShortMessage, err error) {
 			// message: we don't really care
 			messageData:       seg,
 			withoutDataCoding: c.withoutDataCoding,
-			udHeader:          UDH{NewIEConcatMessage(uint8(len(segments)), uint8(i+1), uint8(ref))},
+			udHeader:          udh,
 		})
 	}
 
@@ -205,6 +337,17 @@ func (c *ShortMessage) Split() (multiSM []*ShortMessage, err error) {
 
 // Marshal implements PDU interface.
 func (c *ShortMessage) Marshal(b *ByteBuffer) {
+	// message is carried by message_payload TLV
+	if c.viaPayload {
+		b.Grow(3)
+		if !c.withoutDataCoding {
+			_ = b.WriteByte(c.dataCoding)
+		}
+		_ = b.WriteByte(c.SmDefaultMsgID)
+		_ = b.WriteByte(0)
+		return
+	}
+
 	var (
 		udhBin []byte
 		n      = byte(len(c.messageData))
@@ -261,7 +404,7 @@ func (c *ShortMessage) Unmarshal(b *ByteBuffer, udhi bool) (err error) {
 	c.SetDataCoding(dataCoding)
 
 	// If short message length is non zero, short message contains User-Data Header
-	// Else UDH should be in TLV field MessagePayload
+	// Else UDH should be in TLV field MessagePayload, which is loaded by PDU after optional params are read
 	if udhi && n > 0 {
 		udh := UDH{}
 		_, err = udh.UnmarshalBinary(c.messageData)
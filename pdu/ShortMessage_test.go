@@ -1,6 +1,7 @@
 package pdu
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/linxGnu/gosmpp/data"
@@ -189,3 +190,73 @@ func TestShortMessage(t *testing.T) {
 		}
 	})
 }
+
+func TestMessagePayload(t *testing.T) {
+	parse := func(p PDU) PDU {
+		buf := NewBuffer(nil)
+		p.Marshal(buf)
+
+		parsed, err := Parse(buf)
+		require.NoError(t, err)
+		require.Zero(t, buf.Len())
+		return parsed
+	}
+
+	t.Run("submitSM", func(t *testing.T) {
+		long := strings.Repeat("Thử nghiệm ", 2000) // > 32K octets in UCS2
+
+		v := NewSubmitSM().(*SubmitSM)
+		require.NoError(t, v.SetMessagePayload(long, data.UCS2))
+		require.True(t, v.Message.IsPayload())
+
+		b := NewBuffer(nil)
+		v.Message.Marshal(b)
+		require.Equal(t, "080000", toHex(b.Bytes())) // sm_length is zero
+
+		parsed := parse(v).(*SubmitSM)
+		require.True(t, parsed.Message.IsPayload())
+		message, err := parsed.Message.GetMessage()
+		require.NoError(t, err)
+		require.Equal(t, long, message)
+
+		// set back as short message, dropping message_payload TLV
+		require.NoError(t, v.Message.SetMessageWithEncoding("short", data.GSM7BIT))
+		require.False(t, v.Message.IsPayload())
+
+		parsed = parse(v).(*SubmitSM)
+		require.False(t, parsed.Message.IsPayload())
+		_, found := parsed.OptionalParameters[TagMessagePayload]
+		require.False(t, found)
+		message, err = parsed.Message.GetMessage()
+		require.NoError(t, err)
+		require.Equal(t, "short", message)
+
+		require.Equal(t, errors.ErrMessagePayloadTooLarge, v.SetMessagePayload(strings.Repeat("a", data.SM_MSG_PAYLOAD_LEN+1), data.GSM7BIT))
+	})
+
+	t.Run("deliverSM", func(t *testing.T) {
+		v := NewDeliverSM().(*DeliverSM)
+		v.EsmClass = data.SM_UDH_GSM | data.SM_STORE_FORWARD_MODE
+		v.RegisterOptionalParam(Field{Tag: TagMessagePayload, Data: append([]byte{0x05, 0x00, 0x03, 0x0c, 0x02, 0x01}, "hello"...)})
+
+		parsed := parse(v).(*DeliverSM)
+		require.True(t, parsed.Message.IsPayload())
+		_, _, ref, found := parsed.Message.UDH().GetConcatInfo()
+		require.True(t, found)
+		require.EqualValues(t, 12, ref)
+
+		message, err := parsed.Message.GetMessage()
+		require.NoError(t, err)
+		require.Equal(t, "hello", message)
+	})
+
+	t.Run("dataSM", func(t *testing.T) {
+		v := NewDataSM().(*DataSM)
+		require.NoError(t, v.SetMessagePayload("nghiêng", data.UCS2))
+		require.Equal(t, data.UCS2Coding, v.DataCoding)
+
+		message, err := parse(v).(*DataSM).GetMessagePayload()
+		require.NoError(t, err)
+		require.Equal(t, "nghiêng", message)
+	})
+}
@@ -42,7 +42,7 @@ func TestSubmitMulti(t *testing.T) {
 	v.DestAddrs.Add(d1, d2, d3)
 	require.Equal(t, []DestinationAddress{d1, d2, d3}, v.DestAddrs.Get())
 
-	v.EsmClass = 77
+	v.EsmClass = 13
 	v.ProtocolID = 99
 	v.PriorityFlag = 61
 	v.RegisteredDelivery = 83
@@ -53,7 +53,7 @@ func TestSubmitMulti(t *testing.T) {
 
 	validate(t,
 		v,
-		"0000006e00000021000000000000000d616263001c1d416c696365720003010000426f623100024c6973743100024c69737432004d633d00005300080030006e006700681eaf0020006e00670068006900ea006e00670020006e0067006800691ec5006e00670020006e00671ea3",
+		"0000006e00000021000000000000000d616263001c1d416c696365720003010000426f623100024c6973743100024c69737432000d633d00005300080030006e006700681eaf0020006e00670068006900ea006e00670020006e0067006800691ec5006e00670020006e00671ea3",
 		data.SUBMIT_MULTI,
 	)
 }
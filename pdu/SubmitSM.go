@@ -49,6 +49,15 @@ func (c *SubmitSM) ShouldSplit() bool {
 	return len(c.Message.messageData) > 140
 }
 
+// SetMessagePayload sets message with encoding, carried by message_payload TLV instead of short_message
+// (sm_length is zero). Message payload allows up to 64K octets, thus there is no need for segmentation.
+func (c *SubmitSM) SetMessagePayload(message string, enc data.Encoding) (err error) {
+	if err = c.Message.SetMessagePayloadWithEncoding(message, enc); err == nil {
+		c.RegisterOptionalParam(c.Message.payloadField())
+	}
+	return
+}
+
 // CanResponse implements PDU interface.
 func (c *SubmitSM) CanResponse() bool {
 	return true
@@ -64,20 +73,31 @@ func (c *SubmitSM) GetResponse() PDU {
 // If the message is short enough and doesn't need splitting,
 // Split() returns an array of length 1
 func (c *SubmitSM) Split() (multiSubSM []*SubmitSM, err error) {
+	return c.SplitWithOptions(SplitOptions{})
+}
+
+// SplitWithOptions is similar to Split, with options to choose concatenation mode
+// (8-bit UDH, 16-bit UDH or SAR TLVs) and reference number.
+func (c *SubmitSM) SplitWithOptions(opts SplitOptions) (multiSubSM []*SubmitSM, err error) {
 	multiSubSM = []*SubmitSM{}
 
-	multiMsg, err := c.Message.Split()
+	// all segments will have the same ref id
+	if opts.Reference == 0 {
+		opts.Reference = uint16(getRefNum())
+	}
+
+	multiMsg, err := c.Message.SplitWithOptions(opts)
 	if err != nil {
 		return
 	}
 
-	for _, msg := range multiMsg {
-		multiSubSM = append(multiSubSM, &SubmitSM{
+	for i, msg := range multiMsg {
+		part := &SubmitSM{
 			base:                 c.base,
 			ServiceType:          c.ServiceType,
 			SourceAddr:           c.SourceAddr,
 			DestAddr:             c.DestAddr,
-			EsmClass:             c.EsmClass | data.SM_UDH_GSM, // must set to indicate UDH
+			EsmClass:             c.EsmClass,
 			ProtocolID:           c.ProtocolID,
 			PriorityFlag:         c.PriorityFlag,
 			ScheduleDeliveryTime: c.ScheduleDeliveryTime,
@@ -85,13 +105,31 @@ func (c *SubmitSM) Split() (multiSubSM []*SubmitSM, err error) {
 			RegisteredDelivery:   c.RegisteredDelivery,
 			ReplaceIfPresentFlag: c.ReplaceIfPresentFlag,
 			Message:              *msg,
-		})
+		}
+
+		// each part owns its optional params
+		part.OptionalParameters = make(map[Tag]Field, len(c.OptionalParameters)+3)
+		for tag, field := range c.OptionalParameters {
+			part.OptionalParameters[tag] = field
+		}
+
+		if len(msg.UDH()) > 0 {
+			part.EsmClass |= data.SM_UDH_GSM // must set to indicate UDH
+		}
+
+		if opts.Mode == SplitSAR && len(multiMsg) > 1 {
+			part.SetSarInfo(opts.Reference, byte(len(multiMsg)), byte(i+1))
+		}
+
+		multiSubSM = append(multiSubSM, part)
 	}
 	return
 }
 
 // Marshal implements PDU interface.
 func (c *SubmitSM) Marshal(b *ByteBuffer) {
+	c.Message.syncPayload(c.OptionalParameters)
+
 	c.base.marshal(b, func(b *ByteBuffer) {
 		b.Grow(len(c.ServiceType) + len(c.ScheduleDeliveryTime) + len(c.ValidityPeriod) + 10)
 
@@ -110,8 +148,8 @@ func (c *SubmitSM) Marshal(b *ByteBuffer) {
 }
 
 // Unmarshal implements PDU interface.
-func (c *SubmitSM) Unmarshal(b *ByteBuffer) error {
-	return c.base.unmarshal(b, func(b *ByteBuffer) (err error) {
+func (c *SubmitSM) Unmarshal(b *ByteBuffer) (err error) {
+	if err = c.base.unmarshal(b, func(b *ByteBuffer) (err error) {
 		if c.ServiceType, err = b.ReadCString(); err == nil {
 			if err = c.SourceAddr.Unmarshal(b); err == nil {
 				if err = c.DestAddr.Unmarshal(b); err == nil {
@@ -134,5 +172,9 @@ func (c *SubmitSM) Unmarshal(b *ByteBuffer) error {
 			}
 		}
 		return
-	})
+	}); err == nil {
+		// message might be carried by message_payload
+		err = c.Message.fromPayload(c.OptionalParameters, (c.EsmClass&data.SM_UDH_GSM) > 0)
+	}
+	return
 }
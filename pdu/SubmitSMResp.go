@@ -51,7 +51,8 @@ func (c *SubmitSMResp) Marshal(b *ByteBuffer) {
 // Unmarshal implements PDU interface.
 func (c *SubmitSMResp) Unmarshal(b *ByteBuffer) error {
 	return c.base.unmarshal(b, func(b *ByteBuffer) (err error) {
-		if c.CommandStatus == data.ESME_ROK {
+		// message_id might be omitted when submit is failed
+		if c.CommandStatus == data.ESME_ROK || c.CommandLength > data.PDU_HEADER_SIZE {
 			c.MessageID, err = b.ReadCString()
 		}
 		return
@@ -41,3 +41,79 @@ func TestSubmitSM(t *testing.T) {
 		data.SUBMIT_SM,
 	)
 }
+
+func TestSubmitSMSplit(t *testing.T) {
+	long := "biggest gift của Christmas là có nhiều big/challenging/meaningful problems để sấp mặt làm"
+
+	newLong := func() *SubmitSM {
+		v := NewSubmitSM().(*SubmitSM)
+		v.RegisterOptionalParam(Field{Tag: TagUserMessageReference, Data: []byte{0x00, 0x01}})
+		require.Nil(t, v.Message.SetLongMessageWithEnc(long, data.UCS2))
+		return v
+	}
+
+	t.Run("short", func(t *testing.T) {
+		v := NewSubmitSM().(*SubmitSM)
+		_ = v.Message.SetMessageWithEncoding("short", data.GSM7BIT)
+
+		parts, err := v.Split()
+		require.Nil(t, err)
+		require.Len(t, parts, 1)
+		require.Zero(t, parts[0].EsmClass&data.SM_UDH_GSM)
+	})
+
+	t.Run("udh8bit", func(t *testing.T) {
+		parts, err := newLong().SplitWithOptions(SplitOptions{Reference: 0x1234})
+		require.Nil(t, err)
+		require.Len(t, parts, 2)
+
+		for i, part := range parts {
+			require.NotZero(t, part.EsmClass&data.SM_UDH_GSM)
+			total, seq, ref, found := part.Message.UDH().GetConcatInfo()
+			require.True(t, found)
+			require.EqualValues(t, 2, total)
+			require.EqualValues(t, i+1, seq)
+			require.EqualValues(t, 0x34, ref)
+		}
+	})
+
+	t.Run("udh16bit", func(t *testing.T) {
+		parts, err := newLong().SplitWithOptions(SplitOptions{Mode: SplitUDH16Bit, Reference: 0x1234})
+		require.Nil(t, err)
+		require.Len(t, parts, 2)
+
+		for i, part := range parts {
+			require.NotZero(t, part.EsmClass&data.SM_UDH_GSM)
+			require.LessOrEqual(t, len(part.Message.messageData)+part.Message.UDH().UDHL(), data.SM_GSM_MSG_LEN)
+
+			total, seq, ref, found := part.Message.UDH().GetConcatInfo16()
+			require.True(t, found)
+			require.EqualValues(t, 2, total)
+			require.EqualValues(t, i+1, seq)
+			require.EqualValues(t, 0x1234, ref)
+
+			b := NewBuffer(nil)
+			part.Message.Marshal(b)
+			require.Equal(t, "0608041234", toHex(b.Bytes()[3:8]))
+		}
+	})
+
+	t.Run("sar", func(t *testing.T) {
+		v := newLong()
+		parts, err := v.SplitWithOptions(SplitOptions{Mode: SplitSAR, Reference: 0x1234})
+		require.Nil(t, err)
+		require.Len(t, parts, 2)
+
+		for i, part := range parts {
+			require.Zero(t, part.EsmClass&data.SM_UDH_GSM)
+			require.Empty(t, part.Message.UDH())
+			require.Equal(t, []byte{0x12, 0x34}, part.OptionalParameters[TagSarMsgRefNum].Data)
+			require.Equal(t, []byte{2}, part.OptionalParameters[TagSarTotalSegments].Data)
+			require.Equal(t, []byte{byte(i + 1)}, part.OptionalParameters[TagSarSegmentSeqnum].Data)
+			require.Equal(t, []byte{0x00, 0x01}, part.OptionalParameters[TagUserMessageReference].Data)
+		}
+
+		// original PDU is untouched
+		require.Len(t, v.OptionalParameters, 1)
+	})
+}
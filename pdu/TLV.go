@@ -42,6 +42,7 @@ const (
 	TagLanguageIndicator        Tag = 0x020D
 	TagSarTotalSegments         Tag = 0x020E
 	TagSarSegmentSeqnum         Tag = 0x020F
+	TagScInterfaceVersion       Tag = 0x0210
 	TagCallbackNumPresInd       Tag = 0x0302
 	TagCallbackNumAtag          Tag = 0x0303
 	TagNumberOfMessages         Tag = 0x0304
@@ -77,15 +78,20 @@ func (t *Field) String() string {
 	return string(t.Data)
 }
 
-// Marshal to writer.
+// Marshal to writer. Field without value is skipped, unless its definition
+// allows zero length (e.g. alert_on_message_delivery).
 func (t *Field) Marshal(w *ByteBuffer) {
-	if len(t.Data) > 0 {
-		w.Grow(4 + len(t.Data))
-
-		w.WriteShort(int16(t.Tag))
-		w.WriteShort(int16(len(t.Data)))
-		_, _ = w.Write(t.Data)
+	if len(t.Data) == 0 {
+		if def, found := LookupTLV(t.Tag); !found || def.MinLen > 0 {
+			return
+		}
 	}
+
+	w.Grow(4 + len(t.Data))
+
+	w.WriteShort(int16(t.Tag))
+	w.WriteShort(int16(len(t.Data)))
+	_, _ = w.Write(t.Data)
 }
 
 // Unmarshal from reader.
@@ -94,7 +100,7 @@ func (t *Field) Unmarshal(b *ByteBuffer) (err error) {
 	if tag, err = b.ReadShort(); err == nil {
 		t.Tag = Tag(tag)
 		if ln, err = b.ReadShort(); err == nil {
-			t.Data, err = b.ReadN(int(ln))
+			t.Data, err = b.ReadN(int(uint16(ln)))
 		}
 	}
 	return
@@ -0,0 +1,573 @@
+package pdu
+
+import (
+	"encoding/binary"
+)
+
+// NetworkErrorCode is value of network_error_code TLV.
+type NetworkErrorCode struct {
+	// NetworkType: 1 = ANSI-136, 2 = IS-95, 3 = GSM, ...
+	NetworkType byte
+	ErrorCode   uint16
+}
+
+// CallbackNum is value of callback_num TLV.
+type CallbackNum struct {
+	// DigitModeIndicator: 0 = TBCD, 1 = ASCII.
+	DigitModeIndicator byte
+	Ton                byte
+	Npi                byte
+	Number             string
+}
+
+// CallbackNumAtag is value of callback_num_atag TLV.
+type CallbackNumAtag struct {
+	DataCoding        byte
+	DisplayCharacters []byte
+}
+
+// ItsSessionInfo is value of its_session_info TLV.
+type ItsSessionInfo struct {
+	SessionNumber byte
+
+	// SequenceNumber is sequence number of the dialogue unit within session, 0 to 127.
+	SequenceNumber byte
+
+	// EndOfSession indicates the last message of session.
+	EndOfSession bool
+}
+
+// GetOptionalParam returns optional param by tag.
+func (c *base) GetOptionalParam(tag Tag) (f Field, found bool) {
+	f, found = c.OptionalParameters[tag]
+	return
+}
+
+// SetOptionalParam validates and registers optional param.
+func (c *base) SetOptionalParam(f Field) (err error) {
+	if err = f.Validate(); err == nil {
+		c.RegisterOptionalParam(f)
+	}
+	return
+}
+
+// ValidateOptionalParams validates all optional params against their definitions.
+func (c *base) ValidateOptionalParams() error {
+	for _, f := range c.OptionalParameters {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *base) getInteger(tag Tag) (v uint32, ok bool) {
+	if f, found := c.OptionalParameters[tag]; found {
+		v, ok = f.Integer()
+	}
+	return
+}
+
+func (c *base) setInteger(tag Tag, v uint32) {
+	n := 4
+	if def, found := LookupTLV(tag); found {
+		n = def.MaxLen
+	}
+	c.RegisterOptionalParam(NewIntegerField(tag, v, n))
+}
+
+func (c *base) getCString(tag Tag) (v string, ok bool) {
+	if f, found := c.OptionalParameters[tag]; found {
+		v, ok = f.String(), true
+	}
+	return
+}
+
+func (c *base) getOctets(tag Tag) (v []byte, ok bool) {
+	if f, found := c.OptionalParameters[tag]; found {
+		v, ok = f.Data, true
+	}
+	return
+}
+
+// UserMessageReference returns user_message_reference TLV.
+func (c *base) UserMessageReference() (uint16, bool) {
+	v, ok := c.getInteger(TagUserMessageReference)
+	return uint16(v), ok
+}
+
+// SetUserMessageReference sets user_message_reference TLV.
+func (c *base) SetUserMessageReference(v uint16) {
+	c.setInteger(TagUserMessageReference, uint32(v))
+}
+
+// SourcePort returns source_port TLV.
+func (c *base) SourcePort() (uint16, bool) {
+	v, ok := c.getInteger(TagSourcePort)
+	return uint16(v), ok
+}
+
+// SetSourcePort sets source_port TLV.
+func (c *base) SetSourcePort(v uint16) {
+	c.setInteger(TagSourcePort, uint32(v))
+}
+
+// DestinationPort returns destination_port TLV.
+func (c *base) DestinationPort() (uint16, bool) {
+	v, ok := c.getInteger(TagDestinationPort)
+	return uint16(v), ok
+}
+
+// SetDestinationPort sets destination_port TLV.
+func (c *base) SetDestinationPort(v uint16) {
+	c.setInteger(TagDestinationPort, uint32(v))
+}
+
+// SarInfo returns segmentation info carried by sar_msg_ref_num, sar_total_segments
+// and sar_segment_seqnum TLVs. Returns false if any of them is missing.
+func (c *base) SarInfo() (ref uint16, total, seq byte, ok bool) {
+	r, ok1 := c.getInteger(TagSarMsgRefNum)
+	t, ok2 := c.getInteger(TagSarTotalSegments)
+	s, ok3 := c.getInteger(TagSarSegmentSeqnum)
+	if ok = ok1 && ok2 && ok3; ok {
+		ref, total, seq = uint16(r), byte(t), byte(s)
+	}
+	return
+}
+
+// SetSarInfo sets sar_msg_ref_num, sar_total_segments and sar_segment_seqnum TLVs.
+func (c *base) SetSarInfo(ref uint16, total, seq byte) {
+	c.setInteger(TagSarMsgRefNum, uint32(ref))
+	c.setInteger(TagSarTotalSegments, uint32(total))
+	c.setInteger(TagSarSegmentSeqnum, uint32(seq))
+}
+
+// PayloadType returns payload_type TLV.
+func (c *base) PayloadType() (byte, bool) {
+	v, ok := c.getInteger(TagPayloadType)
+	return byte(v), ok
+}
+
+// SetPayloadType sets payload_type TLV.
+func (c *base) SetPayloadType(v byte) {
+	c.setInteger(TagPayloadType, uint32(v))
+}
+
+// PrivacyIndicator returns privacy_indicator TLV.
+func (c *base) PrivacyIndicator() (byte, bool) {
+	v, ok := c.getInteger(TagPrivacyIndicator)
+	return byte(v), ok
+}
+
+// SetPrivacyIndicator sets privacy_indicator TLV.
+func (c *base) SetPrivacyIndicator(v byte) {
+	c.setInteger(TagPrivacyIndicator, uint32(v))
+}
+
+// LanguageIndicator returns language_indicator TLV.
+func (c *base) LanguageIndicator() (byte, bool) {
+	v, ok := c.getInteger(TagLanguageIndicator)
+	return byte(v), ok
+}
+
+// SetLanguageIndicator sets language_indicator TLV.
+func (c *base) SetLanguageIndicator(v byte) {
+	c.setInteger(TagLanguageIndicator, uint32(v))
+}
+
+// QosTimeToLive returns qos_time_to_live TLV, in seconds.
+func (c *base) QosTimeToLive() (uint32, bool) {
+	return c.getInteger(TagQosTimeToLive)
+}
+
+// SetQosTimeToLive sets qos_time_to_live TLV, in seconds.
+func (c *base) SetQosTimeToLive(v uint32) {
+	c.setInteger(TagQosTimeToLive, v)
+}
+
+// MoreMessagesToSend returns more_messages_to_send TLV.
+func (c *base) MoreMessagesToSend() (byte, bool) {
+	v, ok := c.getInteger(TagMoreMessagesToSend)
+	return byte(v), ok
+}
+
+// SetMoreMessagesToSend sets more_messages_to_send TLV.
+func (c *base) SetMoreMessagesToSend(v byte) {
+	c.setInteger(TagMoreMessagesToSend, uint32(v))
+}
+
+// MessageState returns message_state TLV, one of data.SM_STATE_* constants.
+func (c *base) MessageState() (byte, bool) {
+	v, ok := c.getInteger(TagMessageStateOption)
+	return byte(v), ok
+}
+
+// SetMessageState sets message_state TLV.
+func (c *base) SetMessageState(v byte) {
+	c.setInteger(TagMessageStateOption, uint32(v))
+}
+
+// DeliveryFailureReason returns delivery_failure_reason TLV.
+func (c *base) DeliveryFailureReason() (byte, bool) {
+	v, ok := c.getInteger(TagDeliveryFailureReason)
+	return byte(v), ok
+}
+
+// SetDeliveryFailureReason sets delivery_failure_reason TLV.
+func (c *base) SetDeliveryFailureReason(v byte) {
+	c.setInteger(TagDeliveryFailureReason, uint32(v))
+}
+
+// ReceiptedMessageID returns receipted_message_id TLV.
+func (c *base) ReceiptedMessageID() (string, bool) {
+	return c.getCString(TagReceiptedMessageID)
+}
+
+// SetReceiptedMessageID sets receipted_message_id TLV.
+func (c *base) SetReceiptedMessageID(v string) error {
+	return c.SetOptionalParam(NewCStringField(TagReceiptedMessageID, v))
+}
+
+// AdditionalStatusInfoText returns additional_status_info_text TLV.
+func (c *base) AdditionalStatusInfoText() (string, bool) {
+	return c.getCString(TagAdditionalStatusInfoText)
+}
+
+// SetAdditionalStatusInfoText sets additional_status_info_text TLV.
+func (c *base) SetAdditionalStatusInfoText(v string) error {
+	return c.SetOptionalParam(NewCStringField(TagAdditionalStatusInfoText, v))
+}
+
+// NetworkErrorCode returns network_error_code TLV.
+func (c *base) NetworkErrorCode() (v NetworkErrorCode, ok bool) {
+	if f, found := c.OptionalParameters[TagNetworkErrorCode]; found && len(f.Data) == 3 {
+		v.NetworkType = f.Data[0]
+		v.ErrorCode = binary.BigEndian.Uint16(f.Data[1:])
+		ok = true
+	}
+	return
+}
+
+// SetNetworkErrorCode sets network_error_code TLV.
+func (c *base) SetNetworkErrorCode(v NetworkErrorCode) {
+	c.RegisterOptionalParam(Field{
+		Tag:  TagNetworkErrorCode,
+		Data: []byte{v.NetworkType, byte(v.ErrorCode >> 8), byte(v.ErrorCode)},
+	})
+}
+
+// CallbackNum returns callback_num TLV.
+func (c *base) CallbackNum() (v CallbackNum, ok bool) {
+	if f, found := c.OptionalParameters[TagCallbackNum]; found && len(f.Data) >= 3 {
+		v.DigitModeIndicator, v.Ton, v.Npi = f.Data[0], f.Data[1], f.Data[2]
+		v.Number = string(f.Data[3:])
+		ok = true
+	}
+	return
+}
+
+// SetCallbackNum sets callback_num TLV.
+func (c *base) SetCallbackNum(v CallbackNum) error {
+	return c.SetOptionalParam(Field{
+		Tag:  TagCallbackNum,
+		Data: append([]byte{v.DigitModeIndicator, v.Ton, v.Npi}, v.Number...),
+	})
+}
+
+// DestAddrSubunit returns dest_addr_subunit TLV.
+func (c *base) DestAddrSubunit() (byte, bool) {
+	v, ok := c.getInteger(TagDestAddrSubunit)
+	return byte(v), ok
+}
+
+// SetDestAddrSubunit sets dest_addr_subunit TLV.
+func (c *base) SetDestAddrSubunit(v byte) {
+	c.setInteger(TagDestAddrSubunit, uint32(v))
+}
+
+// SourceAddrSubunit returns source_addr_subunit TLV.
+func (c *base) SourceAddrSubunit() (byte, bool) {
+	v, ok := c.getInteger(TagSourceAddrSubunit)
+	return byte(v), ok
+}
+
+// SetSourceAddrSubunit sets source_addr_subunit TLV.
+func (c *base) SetSourceAddrSubunit(v byte) {
+	c.setInteger(TagSourceAddrSubunit, uint32(v))
+}
+
+// DestNetworkType returns dest_network_type TLV.
+func (c *base) DestNetworkType() (byte, bool) {
+	v, ok := c.getInteger(TagDestNetworkType)
+	return byte(v), ok
+}
+
+// SetDestNetworkType sets dest_network_type TLV.
+func (c *base) SetDestNetworkType(v byte) {
+	c.setInteger(TagDestNetworkType, uint32(v))
+}
+
+// SourceNetworkType returns source_network_type TLV.
+func (c *base) SourceNetworkType() (byte, bool) {
+	v, ok := c.getInteger(TagSourceNetworkType)
+	return byte(v), ok
+}
+
+// SetSourceNetworkType sets source_network_type TLV.
+func (c *base) SetSourceNetworkType(v byte) {
+	c.setInteger(TagSourceNetworkType, uint32(v))
+}
+
+// DestBearerType returns dest_bearer_type TLV.
+func (c *base) DestBearerType() (byte, bool) {
+	v, ok := c.getInteger(TagDestBearerType)
+	return byte(v), ok
+}
+
+// SetDestBearerType sets dest_bearer_type TLV.
+func (c *base) SetDestBearerType(v byte) {
+	c.setInteger(TagDestBearerType, uint32(v))
+}
+
+// SourceBearerType returns source_bearer_type TLV.
+func (c *base) SourceBearerType() (byte, bool) {
+	v, ok := c.getInteger(TagSourceBearerType)
+	return byte(v), ok
+}
+
+// SetSourceBearerType sets source_bearer_type TLV.
+func (c *base) SetSourceBearerType(v byte) {
+	c.setInteger(TagSourceBearerType, uint32(v))
+}
+
+// DestTelematicsID returns dest_telematics_id TLV.
+func (c *base) DestTelematicsID() (uint16, bool) {
+	v, ok := c.getInteger(TagDestTelematicsID)
+	return uint16(v), ok
+}
+
+// SetDestTelematicsID sets dest_telematics_id TLV.
+func (c *base) SetDestTelematicsID(v uint16) {
+	c.setInteger(TagDestTelematicsID, uint32(v))
+}
+
+// SourceTelematicsID returns source_telematics_id TLV.
+func (c *base) SourceTelematicsID() (uint16, bool) {
+	v, ok := c.getInteger(TagSourceTelematicsID)
+	return uint16(v), ok
+}
+
+// SetSourceTelematicsID sets source_telematics_id TLV.
+func (c *base) SetSourceTelematicsID(v uint16) {
+	c.setInteger(TagSourceTelematicsID, uint32(v))
+}
+
+// MsMsgWaitFacilities returns ms_msg_wait_facilities TLV.
+func (c *base) MsMsgWaitFacilities() (byte, bool) {
+	v, ok := c.getInteger(TagMsMsgWaitFacilities)
+	return byte(v), ok
+}
+
+// SetMsMsgWaitFacilities sets ms_msg_wait_facilities TLV.
+func (c *base) SetMsMsgWaitFacilities(v byte) {
+	c.setInteger(TagMsMsgWaitFacilities, uint32(v))
+}
+
+// UserResponseCode returns user_response_code TLV.
+func (c *base) UserResponseCode() (byte, bool) {
+	v, ok := c.getInteger(TagUserResponseCode)
+	return byte(v), ok
+}
+
+// SetUserResponseCode sets user_response_code TLV.
+func (c *base) SetUserResponseCode(v byte) {
+	c.setInteger(TagUserResponseCode, uint32(v))
+}
+
+// ScInterfaceVersion returns sc_interface_version TLV.
+func (c *base) ScInterfaceVersion() (byte, bool) {
+	v, ok := c.getInteger(TagScInterfaceVersion)
+	return byte(v), ok
+}
+
+// SetScInterfaceVersion sets sc_interface_version TLV.
+func (c *base) SetScInterfaceVersion(v byte) {
+	c.setInteger(TagScInterfaceVersion, uint32(v))
+}
+
+// CallbackNumPresInd returns callback_num_pres_ind TLV.
+func (c *base) CallbackNumPresInd() (byte, bool) {
+	v, ok := c.getInteger(TagCallbackNumPresInd)
+	return byte(v), ok
+}
+
+// SetCallbackNumPresInd sets callback_num_pres_ind TLV.
+func (c *base) SetCallbackNumPresInd(v byte) {
+	c.setInteger(TagCallbackNumPresInd, uint32(v))
+}
+
+// NumberOfMessages returns number_of_messages TLV.
+func (c *base) NumberOfMessages() (byte, bool) {
+	v, ok := c.getInteger(TagNumberOfMessages)
+	return byte(v), ok
+}
+
+// SetNumberOfMessages sets number_of_messages TLV.
+func (c *base) SetNumberOfMessages(v byte) {
+	c.setInteger(TagNumberOfMessages, uint32(v))
+}
+
+// DpfResult returns dpf_result TLV.
+func (c *base) DpfResult() (byte, bool) {
+	v, ok := c.getInteger(TagDpfResult)
+	return byte(v), ok
+}
+
+// SetDpfResult sets dpf_result TLV.
+func (c *base) SetDpfResult(v byte) {
+	c.setInteger(TagDpfResult, uint32(v))
+}
+
+// SetDpf returns set_dpf TLV.
+func (c *base) SetDpf() (byte, bool) {
+	v, ok := c.getInteger(TagSetDpf)
+	return byte(v), ok
+}
+
+// SetSetDpf sets set_dpf TLV.
+func (c *base) SetSetDpf(v byte) {
+	c.setInteger(TagSetDpf, uint32(v))
+}
+
+// MsAvailabilityStatus returns ms_availability_status TLV.
+func (c *base) MsAvailabilityStatus() (byte, bool) {
+	v, ok := c.getInteger(TagMsAvailabilityStatus)
+	return byte(v), ok
+}
+
+// SetMsAvailabilityStatus sets ms_availability_status TLV.
+func (c *base) SetMsAvailabilityStatus(v byte) {
+	c.setInteger(TagMsAvailabilityStatus, uint32(v))
+}
+
+// UssdServiceOp returns ussd_service_op TLV.
+func (c *base) UssdServiceOp() (byte, bool) {
+	v, ok := c.getInteger(TagUssdServiceOp)
+	return byte(v), ok
+}
+
+// SetUssdServiceOp sets ussd_service_op TLV.
+func (c *base) SetUssdServiceOp(v byte) {
+	c.setInteger(TagUssdServiceOp, uint32(v))
+}
+
+// DisplayTime returns display_time TLV.
+func (c *base) DisplayTime() (byte, bool) {
+	v, ok := c.getInteger(TagDisplayTime)
+	return byte(v), ok
+}
+
+// SetDisplayTime sets display_time TLV.
+func (c *base) SetDisplayTime(v byte) {
+	c.setInteger(TagDisplayTime, uint32(v))
+}
+
+// SmsSignal returns sms_signal TLV.
+func (c *base) SmsSignal() (uint16, bool) {
+	v, ok := c.getInteger(TagSmsSignal)
+	return uint16(v), ok
+}
+
+// SetSmsSignal sets sms_signal TLV.
+func (c *base) SetSmsSignal(v uint16) {
+	c.setInteger(TagSmsSignal, uint32(v))
+}
+
+// MsValidity returns ms_validity TLV.
+func (c *base) MsValidity() (byte, bool) {
+	v, ok := c.getInteger(TagMsValidity)
+	return byte(v), ok
+}
+
+// SetMsValidity sets ms_validity TLV.
+func (c *base) SetMsValidity(v byte) {
+	c.setInteger(TagMsValidity, uint32(v))
+}
+
+// ItsReplyType returns its_reply_type TLV.
+func (c *base) ItsReplyType() (byte, bool) {
+	v, ok := c.getInteger(TagItsReplyType)
+	return byte(v), ok
+}
+
+// SetItsReplyType sets its_reply_type TLV.
+func (c *base) SetItsReplyType(v byte) {
+	c.setInteger(TagItsReplyType, uint32(v))
+}
+
+// SourceSubaddress returns source_subaddress TLV.
+func (c *base) SourceSubaddress() ([]byte, bool) {
+	return c.getOctets(TagSourceSubaddress)
+}
+
+// SetSourceSubaddress sets source_subaddress TLV.
+func (c *base) SetSourceSubaddress(v []byte) error {
+	return c.SetOptionalParam(Field{Tag: TagSourceSubaddress, Data: v})
+}
+
+// DestSubaddress returns dest_subaddress TLV.
+func (c *base) DestSubaddress() ([]byte, bool) {
+	return c.getOctets(TagDestSubaddress)
+}
+
+// SetDestSubaddress sets dest_subaddress TLV.
+func (c *base) SetDestSubaddress(v []byte) error {
+	return c.SetOptionalParam(Field{Tag: TagDestSubaddress, Data: v})
+}
+
+// CallbackNumAtag returns callback_num_atag TLV.
+func (c *base) CallbackNumAtag() (v CallbackNumAtag, ok bool) {
+	if f, found := c.OptionalParameters[TagCallbackNumAtag]; found && len(f.Data) >= 1 {
+		v.DataCoding, v.DisplayCharacters = f.Data[0], f.Data[1:]
+		ok = true
+	}
+	return
+}
+
+// SetCallbackNumAtag sets callback_num_atag TLV.
+func (c *base) SetCallbackNumAtag(v CallbackNumAtag) error {
+	return c.SetOptionalParam(Field{
+		Tag:  TagCallbackNumAtag,
+		Data: append([]byte{v.DataCoding}, v.DisplayCharacters...),
+	})
+}
+
+// AlertOnMessageDelivery checks if alert_on_message_delivery TLV is present.
+func (c *base) AlertOnMessageDelivery() bool {
+	_, found := c.OptionalParameters[TagAlertOnMessageDelivery]
+	return found
+}
+
+// SetAlertOnMessageDelivery sets alert_on_message_delivery TLV, which has no value.
+func (c *base) SetAlertOnMessageDelivery() {
+	c.RegisterOptionalParam(Field{Tag: TagAlertOnMessageDelivery})
+}
+
+// ItsSessionInfo returns its_session_info TLV.
+func (c *base) ItsSessionInfo() (v ItsSessionInfo, ok bool) {
+	if f, found := c.OptionalParameters[TagItsSessionInfo]; found && len(f.Data) == 2 {
+		v.SessionNumber = f.Data[0]
+		v.SequenceNumber, v.EndOfSession = f.Data[1]>>1, f.Data[1]&0x01 != 0
+		ok = true
+	}
+	return
+}
+
+// SetItsSessionInfo sets its_session_info TLV.
+func (c *base) SetItsSessionInfo(v ItsSessionInfo) {
+	last := v.SequenceNumber << 1
+	if v.EndOfSession {
+		last |= 0x01
+	}
+	c.RegisterOptionalParam(Field{Tag: TagItsSessionInfo, Data: []byte{v.SessionNumber, last}})
+}
@@ -0,0 +1,186 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/linxGnu/gosmpp/data"
+)
+
+// TLVType is value type of TLV.
+type TLVType byte
+
+const (
+	// TLVOctets is octet string value.
+	TLVOctets TLVType = iota
+
+	// TLVCString is null terminated octet string value.
+	TLVCString
+
+	// TLVInteger is big-endian unsigned integer value.
+	TLVInteger
+)
+
+// TLVDefinition describes value type and length boundary of a TLV.
+type TLVDefinition struct {
+	Tag    Tag
+	Name   string
+	Type   TLVType
+	MinLen int
+	MaxLen int
+}
+
+// InvalidTLVError indicates TLV violates its definition.
+type InvalidTLVError struct {
+	Field      Field
+	Definition TLVDefinition
+}
+
+// Error implements error interface.
+func (e *InvalidTLVError) Error() string {
+	if l := len(e.Field.Data); e.Definition.Type == TLVCString && l > 0 && e.Field.Data[l-1] != 0 && l < e.Definition.MaxLen {
+		return fmt.Sprintf("Invalid TLV %s (0x%04x): value is not null terminated", e.Definition.Name, uint16(e.Field.Tag))
+	}
+	return fmt.Sprintf("Invalid TLV %s (0x%04x): length %d is out of range [%d, %d]",
+		e.Definition.Name, uint16(e.Field.Tag), len(e.Field.Data), e.Definition.MinLen, e.Definition.MaxLen)
+}
+
+var (
+	tlvLock        sync.RWMutex
+	tlvDefinitions = map[Tag]TLVDefinition{}
+)
+
+func init() {
+	for _, def := range []TLVDefinition{
+		{TagDestAddrSubunit, "dest_addr_subunit", TLVInteger, 1, 1},
+		{TagDestNetworkType, "dest_network_type", TLVInteger, 1, 1},
+		{TagDestBearerType, "dest_bearer_type", TLVInteger, 1, 1},
+		{TagDestTelematicsID, "dest_telematics_id", TLVInteger, 2, 2},
+		{TagSourceAddrSubunit, "source_addr_subunit", TLVInteger, 1, 1},
+		{TagSourceNetworkType, "source_network_type", TLVInteger, 1, 1},
+		{TagSourceBearerType, "source_bearer_type", TLVInteger, 1, 1},
+		{TagSourceTelematicsID, "source_telematics_id", TLVInteger, 1, 1},
+		{TagQosTimeToLive, "qos_time_to_live", TLVInteger, data.OPT_PAR_QOS_TIME_TO_LIVE_MIN, data.OPT_PAR_QOS_TIME_TO_LIVE_MAX},
+		{TagPayloadType, "payload_type", TLVInteger, 1, 1},
+		{TagAdditionalStatusInfoText, "additional_status_info_text", TLVCString, data.OPT_PAR_ADD_STAT_INFO_MIN, data.OPT_PAR_ADD_STAT_INFO_MAX},
+		{TagReceiptedMessageID, "receipted_message_id", TLVCString, data.OPT_PAR_RECP_MSG_ID_MIN, data.OPT_PAR_RECP_MSG_ID_MAX},
+		{TagMsMsgWaitFacilities, "ms_msg_wait_facilities", TLVInteger, 1, 1},
+		{TagPrivacyIndicator, "privacy_indicator", TLVInteger, 1, 1},
+		{TagSourceSubaddress, "source_subaddress", TLVOctets, data.OPT_PAR_SRC_SUBADDR_MIN, data.OPT_PAR_SRC_SUBADDR_MAX},
+		{TagDestSubaddress, "dest_subaddress", TLVOctets, data.OPT_PAR_DEST_SUBADDR_MIN, data.OPT_PAR_DEST_SUBADDR_MAX},
+		{TagUserMessageReference, "user_message_reference", TLVInteger, 2, 2},
+		{TagUserResponseCode, "user_response_code", TLVInteger, 1, 1},
+		{TagSourcePort, "source_port", TLVInteger, 2, 2},
+		{TagDestinationPort, "destination_port", TLVInteger, 2, 2},
+		{TagSarMsgRefNum, "sar_msg_ref_num", TLVInteger, 2, 2},
+		{TagLanguageIndicator, "language_indicator", TLVInteger, 1, 1},
+		{TagSarTotalSegments, "sar_total_segments", TLVInteger, 1, 1},
+		{TagSarSegmentSeqnum, "sar_segment_seqnum", TLVInteger, 1, 1},
+		{TagScInterfaceVersion, "sc_interface_version", TLVInteger, 1, 1},
+		{TagCallbackNumPresInd, "callback_num_pres_ind", TLVInteger, 1, 1},
+		{TagCallbackNumAtag, "callback_num_atag", TLVOctets, data.OPT_PAR_CALLBACK_NUM_ATAG_MIN, data.OPT_PAR_CALLBACK_NUM_ATAG_MAX},
+		{TagNumberOfMessages, "number_of_messages", TLVInteger, 1, 1},
+		{TagCallbackNum, "callback_num", TLVOctets, data.OPT_PAR_CALLBACK_NUM_MIN, data.OPT_PAR_CALLBACK_NUM_MAX},
+		{TagDpfResult, "dpf_result", TLVInteger, 1, 1},
+		{TagSetDpf, "set_dpf", TLVInteger, 1, 1},
+		{TagMsAvailabilityStatus, "ms_availability_status", TLVInteger, 1, 1},
+		{TagNetworkErrorCode, "network_error_code", TLVOctets, data.OPT_PAR_NW_ERR_CODE_MIN, data.OPT_PAR_NW_ERR_CODE_MAX},
+		// SMPP 3.4 allows message_payload up to 64K octets, OPT_PAR_MSG_PAYLOAD_MAX is too strict
+		{TagMessagePayload, "message_payload", TLVOctets, data.OPT_PAR_MSG_PAYLOAD_MIN, data.SM_MSG_PAYLOAD_LEN},
+		{TagDeliveryFailureReason, "delivery_failure_reason", TLVInteger, 1, 1},
+		{TagMoreMessagesToSend, "more_messages_to_send", TLVInteger, 1, 1},
+		{TagMessageStateOption, "message_state", TLVInteger, 1, 1},
+		{TagUssdServiceOp, "ussd_service_op", TLVInteger, 1, 1},
+		{TagDisplayTime, "display_time", TLVInteger, 1, 1},
+		{TagSmsSignal, "sms_signal", TLVInteger, 2, 2},
+		{TagMsValidity, "ms_validity", TLVInteger, 1, 1},
+		{TagAlertOnMessageDelivery, "alert_on_message_delivery", TLVOctets, 0, 0},
+		{TagItsReplyType, "its_reply_type", TLVInteger, 1, 1},
+		{TagItsSessionInfo, "its_session_info", TLVOctets, 2, 2},
+	} {
+		tlvDefinitions[def.Tag] = def
+	}
+}
+
+// RegisterTLV registers definition of TLV, overriding existing one.
+// It could be used for vendor specific TLVs.
+func RegisterTLV(def TLVDefinition) {
+	tlvLock.Lock()
+	tlvDefinitions[def.Tag] = def
+	tlvLock.Unlock()
+}
+
+// LookupTLV returns definition of TLV.
+func LookupTLV(tag Tag) (def TLVDefinition, found bool) {
+	tlvLock.RLock()
+	def, found = tlvDefinitions[tag]
+	tlvLock.RUnlock()
+	return
+}
+
+// Validate field against its definition. Fields without definition are always valid.
+func (t *Field) Validate() error {
+	return t.validate(false)
+}
+
+// validate field against its definition. Lenient validation accepts C-string value
+// without null terminator, which is common in real traffic.
+func (t *Field) validate(lenient bool) error {
+	def, found := LookupTLV(t.Tag)
+	if !found {
+		return nil
+	}
+
+	l := len(t.Data)
+	if def.Type == TLVCString && l > 0 && t.Data[l-1] != 0 {
+		if !lenient {
+			return &InvalidTLVError{Field: *t, Definition: def}
+		}
+		l++ // as if terminated
+	}
+
+	if l < def.MinLen || l > def.MaxLen {
+		return &InvalidTLVError{Field: *t, Definition: def}
+	}
+
+	return nil
+}
+
+// NewIntegerField returns TLV carrying big-endian integer of n octets (1, 2 or 4).
+func NewIntegerField(tag Tag, v uint32, n int) (f Field) {
+	f.Tag = tag
+
+	switch n {
+	case 1:
+		f.Data = []byte{byte(v)}
+
+	case 2:
+		f.Data = make([]byte, 2)
+		binary.BigEndian.PutUint16(f.Data, uint16(v))
+
+	default:
+		f.Data = make([]byte, 4)
+		binary.BigEndian.PutUint32(f.Data, v)
+	}
+
+	return
+}
+
+// NewCStringField returns TLV carrying null terminated string.
+func NewCStringField(tag Tag, v string) Field {
+	return Field{Tag: tag, Data: append([]byte(v), 0)}
+}
+
+// Integer returns big-endian integer value of field. Returns false if length of value
+// is not in range of 1 to 4 octets.
+func (t *Field) Integer() (v uint32, ok bool) {
+	if len(t.Data) == 0 || len(t.Data) > 4 {
+		return
+	}
+
+	for _, b := range t.Data {
+		v = v<<8 | uint32(b)
+	}
+	return v, true
+}
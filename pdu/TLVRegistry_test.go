@@ -0,0 +1,210 @@
+package pdu
+
+import (
+	"testing"
+
+	"github.com/linxGnu/gosmpp/data"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLVRegistry(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		f := NewIntegerField(TagSourcePort, 0x1234, 2)
+		require.Equal(t, []byte{0x12, 0x34}, f.Data)
+		require.Nil(t, f.Validate())
+
+		f = Field{Tag: TagSourcePort, Data: []byte{1}}
+		err := f.Validate()
+		require.IsType(t, &InvalidTLVError{}, err)
+		require.Contains(t, err.Error(), "source_port")
+
+		f = Field{Tag: TagReceiptedMessageID, Data: []byte("abc")}
+		require.Contains(t, f.Validate().Error(), "not null terminated")
+
+		// unknown tag is always valid
+		f = Field{Tag: 0x1400, Data: []byte{1, 2, 3}}
+		require.Nil(t, f.Validate())
+
+		RegisterTLV(TLVDefinition{Tag: 0x1400, Name: "vendor", Type: TLVInteger, MinLen: 4, MaxLen: 4})
+		require.NotNil(t, f.Validate())
+
+		def, found := LookupTLV(0x1400)
+		require.True(t, found)
+		require.Equal(t, "vendor", def.Name)
+	})
+
+	t.Run("accessors", func(t *testing.T) {
+		s := NewSubmitSM().(*SubmitSM)
+
+		_, ok := s.UserMessageReference()
+		require.False(t, ok)
+
+		s.SetUserMessageReference(0x0102)
+		s.SetSourcePort(8080)
+		s.SetDestinationPort(9090)
+		s.SetSarInfo(0xABCD, 3, 2)
+		s.SetPayloadType(1)
+		s.SetQosTimeToLive(3600)
+		s.SetMessageState(data.SM_STATE_DELIVERED)
+		s.SetNetworkErrorCode(NetworkErrorCode{NetworkType: 3, ErrorCode: 0x0B0C})
+		require.Nil(t, s.SetReceiptedMessageID("id-1"))
+		require.Nil(t, s.SetCallbackNum(CallbackNum{DigitModeIndicator: 1, Ton: 1, Npi: 1, Number: "1234"}))
+		require.Nil(t, s.ValidateOptionalParams())
+
+		require.Equal(t, []byte{0x01, 0x02}, s.OptionalParameters[TagUserMessageReference].Data)
+
+		// marshal and parse back
+		buf := NewBuffer(nil)
+		s.Marshal(buf)
+		p, err := Parse(buf)
+		require.Nil(t, err)
+		parsed := p.(*SubmitSM)
+
+		ref, ok := parsed.UserMessageReference()
+		require.True(t, ok)
+		require.EqualValues(t, 0x0102, ref)
+
+		port, _ := parsed.SourcePort()
+		require.EqualValues(t, 8080, port)
+		port, _ = parsed.DestinationPort()
+		require.EqualValues(t, 9090, port)
+
+		sarRef, total, seq, ok := parsed.SarInfo()
+		require.True(t, ok)
+		require.EqualValues(t, 0xABCD, sarRef)
+		require.EqualValues(t, 3, total)
+		require.EqualValues(t, 2, seq)
+
+		payloadType, _ := parsed.PayloadType()
+		require.EqualValues(t, 1, payloadType)
+
+		ttl, _ := parsed.QosTimeToLive()
+		require.EqualValues(t, 3600, ttl)
+
+		state, _ := parsed.MessageState()
+		require.EqualValues(t, data.SM_STATE_DELIVERED, state)
+
+		code, ok := parsed.NetworkErrorCode()
+		require.True(t, ok)
+		require.Equal(t, NetworkErrorCode{NetworkType: 3, ErrorCode: 0x0B0C}, code)
+
+		id, _ := parsed.ReceiptedMessageID()
+		require.Equal(t, "id-1", id)
+
+		callback, ok := parsed.CallbackNum()
+		require.True(t, ok)
+		require.Equal(t, "1234", callback.Number)
+	})
+
+	t.Run("typedAccessors", func(t *testing.T) {
+		s := NewDataSM().(*DataSM)
+
+		s.SetDestAddrSubunit(2)
+		s.SetSourceNetworkType(3)
+		s.SetDestTelematicsID(0x0102)
+		s.SetSourceTelematicsID(4)
+		s.SetMsMsgWaitFacilities(0x80)
+		s.SetSmsSignal(0x0304)
+		s.SetSetDpf(1)
+		s.SetAlertOnMessageDelivery()
+		s.SetItsSessionInfo(ItsSessionInfo{SessionNumber: 5, SequenceNumber: 6, EndOfSession: true})
+		require.Nil(t, s.SetSourceSubaddress([]byte{0xA0, 0x01}))
+		require.Nil(t, s.SetCallbackNumAtag(CallbackNumAtag{DataCoding: 1, DisplayCharacters: []byte("home")}))
+		require.Nil(t, s.ValidateOptionalParams())
+
+		require.False(t, NewDataSM().(*DataSM).AlertOnMessageDelivery())
+
+		buf := NewBuffer(nil)
+		s.Marshal(buf)
+		p, err := Parse(buf)
+		require.Nil(t, err)
+		parsed := p.(*DataSM)
+
+		subunit, ok := parsed.DestAddrSubunit()
+		require.True(t, ok)
+		require.EqualValues(t, 2, subunit)
+
+		network, _ := parsed.SourceNetworkType()
+		require.EqualValues(t, 3, network)
+
+		telematics, _ := parsed.DestTelematicsID()
+		require.EqualValues(t, 0x0102, telematics)
+		telematics, _ = parsed.SourceTelematicsID()
+		require.EqualValues(t, 4, telematics)
+
+		waiting, _ := parsed.MsMsgWaitFacilities()
+		require.EqualValues(t, 0x80, waiting)
+
+		signal, _ := parsed.SmsSignal()
+		require.EqualValues(t, 0x0304, signal)
+
+		dpf, _ := parsed.SetDpf()
+		require.EqualValues(t, 1, dpf)
+
+		require.True(t, parsed.AlertOnMessageDelivery())
+
+		info, ok := parsed.ItsSessionInfo()
+		require.True(t, ok)
+		require.Equal(t, ItsSessionInfo{SessionNumber: 5, SequenceNumber: 6, EndOfSession: true}, info)
+
+		subaddress, _ := parsed.SourceSubaddress()
+		require.Equal(t, []byte{0xA0, 0x01}, subaddress)
+
+		atag, ok := parsed.CallbackNumAtag()
+		require.True(t, ok)
+		require.Equal(t, CallbackNumAtag{DataCoding: 1, DisplayCharacters: []byte("home")}, atag)
+
+		_, ok = parsed.UssdServiceOp()
+		require.False(t, ok)
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		s := NewSubmitSM().(*SubmitSM)
+		s.RegisterOptionalParam(Field{Tag: TagSarTotalSegments, Data: []byte{1, 2}})
+		s.SetSourcePort(1)
+		require.IsType(t, &InvalidTLVError{}, s.ValidateOptionalParams())
+
+		// PDU is parsed completely and returned along with error
+		buf := NewBuffer(nil)
+		s.Marshal(buf)
+		p, err := Parse(buf)
+		require.IsType(t, &InvalidTLVError{}, err)
+		require.NotNil(t, p)
+
+		port, ok := p.(*SubmitSM).SourcePort()
+		require.True(t, ok)
+		require.EqualValues(t, 1, port)
+
+		// C-string without null terminator is accepted
+		d := NewDeliverSM().(*DeliverSM)
+		d.RegisterOptionalParam(Field{Tag: TagReceiptedMessageID, Data: []byte("msg-1")})
+		require.NotNil(t, d.ValidateOptionalParams())
+
+		buf = NewBuffer(nil)
+		d.Marshal(buf)
+		p, err = Parse(buf)
+		require.Nil(t, err)
+
+		id, ok := p.(*DeliverSM).ReceiptedMessageID()
+		require.True(t, ok)
+		require.Equal(t, "msg-1", id)
+	})
+
+	t.Run("zeroLength", func(t *testing.T) {
+		s := NewSubmitSM().(*SubmitSM)
+		require.Nil(t, s.SetOptionalParam(Field{Tag: TagAlertOnMessageDelivery}))
+		s.RegisterOptionalParam(Field{Tag: TagSourcePort})
+
+		// flag TLV is kept, TLV requiring value is dropped
+		buf := NewBuffer(nil)
+		s.Marshal(buf)
+		p, err := Parse(buf)
+		require.Nil(t, err)
+
+		_, found := p.(*SubmitSM).GetOptionalParam(TagAlertOnMessageDelivery)
+		require.True(t, found)
+		_, found = p.(*SubmitSM).GetOptionalParam(TagSourcePort)
+		require.False(t, found)
+	})
+}
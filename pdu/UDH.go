@@ -165,6 +165,21 @@ func (u UDH) GetConcatInfo() (totalParts, partNum, mref byte, found bool) {
 	return
 }
 
+// GetConcatInfo16 return the FIRST concatenated message IE, either with 8-bit (IE 0x00)
+// or 16-bit (IE 0x08) reference number.
+func (u UDH) GetConcatInfo16() (totalParts, partNum byte, mref uint16, found bool) {
+	for i := range u {
+		switch ie := u[i]; {
+		case ie.ID == data.UDH_CONCAT_MSG_8_BIT_REF && len(ie.Data) == 3:
+			return ie.Data[1], ie.Data[2], uint16(ie.Data[0]), true
+
+		case ie.ID == data.UDH_CONCAT_MSG_16_BIT_REF && len(ie.Data) == 4:
+			return ie.Data[2], ie.Data[3], uint16(ie.Data[0])<<8 | uint16(ie.Data[1]), true
+		}
+	}
+	return
+}
+
 // InfoElement represent a 3 parts Information-Element
 // as defined in 3GPP TS 23.040 Section 9.2.3.24
 // Each InfoElement is comprised of it's identifier and data
@@ -182,6 +197,15 @@ func NewIEConcatMessage(totalParts, partNum, mref byte) InfoElement {
 	}
 }
 
+// NewIEConcatMessage16 turn a new IE element for concat message info, with 16-bit reference number
+// IE.Data is populated at time of object creation
+func NewIEConcatMessage16(totalParts, partNum byte, mref uint16) InfoElement {
+	return InfoElement{
+		ID:   data.UDH_CONCAT_MSG_16_BIT_REF,
+		Data: []byte{byte(mref >> 8), byte(mref), totalParts, partNum},
+	}
+}
+
 // UnmarshalBinary unmarshal IE from binary in src, only read a single IE,
 // expect src at least of length 2 with correct IE format:
 //		[ ID_1, LENGTH_1, DATA_N ]
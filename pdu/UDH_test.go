@@ -39,6 +39,27 @@ func TestUserDataHeader(t *testing.T) {
 		require.Equal(t, "0500030c0201", toHex(b))
 	})
 
+	t.Run("concatInfo (16 bit)", func(t *testing.T) {
+		u := new(UDH)
+		_, err := u.UnmarshalBinary([]byte{0x06, 0x08, 0x04, 0x01, 0x02, 0x03, 0x02})
+		require.NoError(t, err)
+
+		totalParts, sequence, reference, found := u.GetConcatInfo16()
+		require.True(t, found)
+		require.Equal(t, byte(3), totalParts)
+		require.Equal(t, byte(2), sequence)
+		require.Equal(t, uint16(0x0102), reference)
+
+		_, _, _, found = u.GetConcatInfo()
+		require.False(t, found)
+
+		totalParts, sequence, reference, found = UDH{NewIEConcatMessage(2, 1, 12)}.GetConcatInfo16()
+		require.True(t, found)
+		require.Equal(t, byte(2), totalParts)
+		require.Equal(t, byte(1), sequence)
+		require.Equal(t, uint16(12), reference)
+	})
+
 	t.Run("unmarshalBinaryUDHConcatMessage failed", func(t *testing.T) {
 		failedList := [][]byte{
 			{0x04, 0x00, 0x02, 0x02, 0x01},
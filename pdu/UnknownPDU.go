@@ -0,0 +1,52 @@
+package pdu
+
+import (
+	"github.com/linxGnu/gosmpp/data"
+)
+
+// UnknownPDU is a well-framed PDU with unknown or unsupported command id, e.g. vendor-specific one.
+// Its body is kept raw.
+//
+// Unknown request is responded with generic_nack, carrying ESME_RINVCMDID.
+type UnknownPDU struct {
+	base
+	Body []byte
+}
+
+// NewUnknownPDU returns new UnknownPDU with given command id and raw body.
+func NewUnknownPDU(commandID data.CommandIDType, body []byte) PDU {
+	c := &UnknownPDU{
+		base: newBase(),
+		Body: body,
+	}
+	c.CommandID = commandID
+	return c
+}
+
+// CanResponse implements PDU interface. Only unknown request could be responded.
+func (c *UnknownPDU) CanResponse() bool {
+	return c.CommandID >= 0
+}
+
+// GetResponse implements PDU interface.
+func (c *UnknownPDU) GetResponse() PDU {
+	resp := NewGenericNack()
+	resp.SetCommandStatus(data.ESME_RINVCMDID)
+	resp.SetSequenceNumber(c.SequenceNumber)
+	return resp
+}
+
+// Marshal implements PDU interface.
+func (c *UnknownPDU) Marshal(b *ByteBuffer) {
+	c.base.marshal(b, func(b *ByteBuffer) {
+		_, _ = b.Write(c.Body)
+	})
+}
+
+// Unmarshal implements PDU interface.
+func (c *UnknownPDU) Unmarshal(b *ByteBuffer) error {
+	return c.base.unmarshal(b, func(b *ByteBuffer) (err error) {
+		c.Body, err = b.ReadN(int(c.CommandLength) - data.PDU_HEADER_SIZE)
+		return
+	})
+}
@@ -0,0 +1,35 @@
+package pdu
+
+import (
+	"testing"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownPDU(t *testing.T) {
+	v := NewUnknownPDU(0x00010200, []byte{0x01, 0x02, 0x03}).(*UnknownPDU)
+	require.True(t, v.CanResponse())
+	v.SequenceNumber = 13
+
+	buf := NewBuffer(nil)
+	v.Marshal(buf)
+	require.Equal(t, fromHex("0000001300010200000000000000000d010203"), buf.Bytes())
+
+	// well-framed PDU is parsed, but reported as unknown
+	p, err := Parse(buf)
+	require.Equal(t, errors.ErrUnknownCommandID, err)
+	require.Equal(t, v, p)
+	require.Zero(t, buf.Len())
+
+	validate(t,
+		v.GetResponse(),
+		"0000001080000000000000030000000d",
+		data.GENERIC_NACK,
+	)
+
+	// unknown response could not be responded
+	require.False(t, NewUnknownPDU(-0x7ffefe00, nil).CanResponse())
+}
@@ -1,6 +1,7 @@
 package gosmpp
 
 import (
+	"context"
 	"io"
 
 	"github.com/linxGnu/gosmpp/pdu"
@@ -10,6 +11,8 @@ import (
 type Transceiver interface {
 	io.Closer
 	Submit(pdu.PDU) error
+	SubmitAndWait(context.Context, pdu.PDU) (pdu.PDU, error)
+	Shutdown(context.Context) error
 	SystemID() string
 }
 
@@ -17,11 +20,14 @@ type Transceiver interface {
 type Transmitter interface {
 	io.Closer
 	Submit(pdu.PDU) error
+	SubmitAndWait(context.Context, pdu.PDU) (pdu.PDU, error)
+	Shutdown(context.Context) error
 	SystemID() string
 }
 
 // Receiver interface.
 type Receiver interface {
 	io.Closer
+	Shutdown(context.Context) error
 	SystemID() string
 }
@@ -0,0 +1,324 @@
+package gosmpp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrPoolClosed indicates pool is closed. Can not send any PDU.
+	ErrPoolClosed = fmt.Errorf("Pool is closed. Can not send PDU to SMSC")
+
+	// ErrNoHealthyBind indicates all binds of pool are rebinding or closed.
+	ErrNoHealthyBind = fmt.Errorf("No healthy bind in pool")
+
+	// ErrPoolNotTransmitter indicates pool of receivers can not send PDU.
+	ErrPoolNotTransmitter = fmt.Errorf("Pool of receivers can not send PDU to SMSC")
+)
+
+// Balancer decides how pool distributes PDU(s) across its binds.
+type Balancer byte
+
+const (
+	// RoundRobin picks healthy binds in turn.
+	RoundRobin Balancer = iota
+
+	// LeastInFlight picks healthy bind with the least outstanding requests.
+	LeastInFlight
+)
+
+// PoolSettings is settings for Pool.
+type PoolSettings struct {
+	// Size is number of binds.
+	//
+	// Default: 1
+	Size int
+
+	// MaxBinds is total number of binds SMSC allows for the account. Size is capped to it.
+	//
+	// Zero means no limit.
+	MaxBinds int
+
+	// Balancer distributes submitted PDU(s) across healthy binds.
+	//
+	// Default: RoundRobin
+	Balancer Balancer
+
+	// RebindingInterval is rebinding interval of each bind session.
+	// See NewTransceiverSession for more detail.
+	RebindingInterval time.Duration
+
+	// Settings of each bind. Fields which are irrelevant to binding type of pool are ignored.
+	// OnPDU receives PDU(s) from all binds.
+	//
+	// OutboundStore is not supported by pool, hence ignored.
+	//
+	// WindowWait bounds total duration which Submit waits for a free slot, across all binds.
+	Settings TransceiveSettings
+}
+
+func (s *PoolSettings) normalize() {
+	if s.Size <= 0 {
+		s.Size = 1
+	}
+
+	if s.MaxBinds > 0 && s.Size > s.MaxBinds {
+		s.Size = s.MaxBinds
+	}
+}
+
+// Pool manages multiple bind sessions with the same credentials and distributes
+// submitted PDU(s) across healthy ones. Binds are skipped while they are rebinding.
+type Pool struct {
+	settings    PoolSettings
+	bindingType pdu.BindingType
+	binds       []*session
+	next        uint32
+	state       int32
+}
+
+// NewPool binds sessions of given binding type and returns pool of them.
+func NewPool(dialer Dialer, auth Auth, bindingType pdu.BindingType, settings PoolSettings) (p *Pool, err error) {
+	return NewPoolContext(context.Background(), dialer, auth, bindingType, settings)
+}
+
+// NewPoolContext binds sessions of given binding type and returns pool of them, like NewPool.
+// Dialing and binding of the first connections are canceled once context is done.
+func NewPoolContext(ctx context.Context, dialer Dialer, auth Auth, bindingType pdu.BindingType, settings PoolSettings) (p *Pool, err error) {
+	settings.normalize()
+
+	pool := &Pool{
+		settings:    settings,
+		bindingType: bindingType,
+		binds:       make([]*session, 0, settings.Size),
+	}
+
+	for i := 0; i < settings.Size; i++ {
+		var s *session
+		if s, err = pool.bind(ctx, dialer, auth); err != nil {
+			_ = pool.Close()
+			return
+		}
+		pool.binds = append(pool.binds, s)
+	}
+
+	return pool, nil
+}
+
+func (p *Pool) bind(ctx context.Context, dialer Dialer, auth Auth) (*session, error) {
+	s, interval := p.settings.Settings, p.settings.RebindingInterval
+	s.OutboundStore = nil
+
+	switch p.bindingType {
+	case pdu.Transmitter:
+		session, err := NewTransmitterSessionContext(ctx, dialer, auth, s.transmit(), interval)
+		if err != nil {
+			return nil, err
+		}
+		return session.session, nil
+
+	case pdu.Receiver:
+		session, err := NewReceiverSessionContext(ctx, dialer, auth, s.receive(), interval)
+		if err != nil {
+			return nil, err
+		}
+		return session.session, nil
+
+	default:
+		session, err := NewTransceiverSessionContext(ctx, dialer, auth, s, interval)
+		if err != nil {
+			return nil, err
+		}
+		return session.session, nil
+	}
+}
+
+// Size returns number of binds.
+func (p *Pool) Size() int {
+	return len(p.binds)
+}
+
+// Healthy returns number of healthy binds.
+func (p *Pool) Healthy() (n int) {
+	for _, s := range p.binds {
+		if healthy(s) {
+			n++
+		}
+	}
+	return
+}
+
+// Close all bind sessions.
+func (p *Pool) Close() (err error) {
+	if atomic.CompareAndSwapInt32(&p.state, 0, 1) {
+		for _, s := range p.binds {
+			if e := s.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return
+}
+
+// Shutdown all bind sessions gracefully, in parallel. See Transceiver.Shutdown.
+func (p *Pool) Shutdown(ctx context.Context) (err error) {
+	if atomic.CompareAndSwapInt32(&p.state, 0, 1) {
+		errs := make([]error, len(p.binds))
+
+		var wg sync.WaitGroup
+		for i, s := range p.binds {
+			wg.Add(1)
+			go func(i int, s *session) {
+				errs[i] = s.Shutdown(ctx)
+				wg.Done()
+			}(i, s)
+		}
+		wg.Wait()
+
+		for _, e := range errs {
+			if e != nil {
+				return e
+			}
+		}
+	}
+	return
+}
+
+// Submit a PDU through one of healthy binds.
+func (p *Pool) Submit(pd pdu.PDU) error {
+	return p.submit(func(t *transmitter, windowWait time.Duration) (err error) {
+		_, err = t.submit(context.Background(), pd, false, windowWait)
+		return
+	})
+}
+
+// SubmitAndWait submits a PDU through one of healthy binds and waits for its response from SMSC.
+func (p *Pool) SubmitAndWait(ctx context.Context, pd pdu.PDU) (resp pdu.PDU, err error) {
+	err = p.submit(func(t *transmitter, windowWait time.Duration) (err error) {
+		resp, err = t.submitAndWait(ctx, pd, windowWait)
+		return
+	})
+	return
+}
+
+// submit tries healthy binds in order picked by balancer, until one accepts PDU.
+//
+// Binds are tried without waiting for free slot in window first. If all of them are full,
+// they are tried again, waiting for WindowWait in total.
+func (p *Pool) submit(fn func(t *transmitter, windowWait time.Duration) error) (err error) {
+	if atomic.LoadInt32(&p.state) != 0 {
+		return ErrPoolClosed
+	}
+
+	if p.bindingType == pdu.Receiver {
+		return ErrPoolNotTransmitter
+	}
+
+	binds := p.candidates()
+
+	err = ErrNoHealthyBind
+	for _, s := range binds {
+		t, ok := s.current().(*transceiver)
+		if !ok {
+			continue
+		}
+
+		// bind might be closed just after picked, or its window is full
+		if err = fn(t.out, 0); err != ErrTransmitterClosing && err != ErrWindowFull {
+			return
+		}
+	}
+
+	windowWait := p.settings.Settings.WindowWait
+	if err != ErrWindowFull || windowWait == 0 {
+		return
+	}
+
+	deadline := time.Now().Add(windowWait)
+	for _, s := range binds {
+		t, ok := s.current().(*transceiver)
+		if !ok {
+			continue
+		}
+
+		// negative WindowWait waits until a slot is released
+		wait := windowWait
+		if wait > 0 {
+			if wait = time.Until(deadline); wait <= 0 {
+				return ErrWindowFull
+			}
+		}
+
+		// only closed bind is skipped, since waiting is done already
+		if err = fn(t.out, wait); err != ErrTransmitterClosing {
+			return
+		}
+	}
+
+	return
+}
+
+// candidates returns healthy binds, ordered by balancer.
+func (p *Pool) candidates() (binds []*session) {
+	n := len(p.binds)
+	start := int(atomic.AddUint32(&p.next, 1) % uint32(n))
+
+	binds = make([]*session, 0, n)
+	for i := 0; i < n; i++ {
+		if s := p.binds[(start+i)%n]; healthy(s) {
+			binds = append(binds, s)
+		}
+	}
+
+	if p.settings.Balancer == LeastInFlight {
+		// stable insertion sort keeps round robin order among equal ones
+		counts := make([]int, len(binds))
+		for i, s := range binds {
+			counts[i] = inflight(s.current())
+		}
+
+		for i := 1; i < len(binds); i++ {
+			for j := i; j > 0 && counts[j] < counts[j-1]; j-- {
+				binds[j], binds[j-1] = binds[j-1], binds[j]
+				counts[j], counts[j-1] = counts[j-1], counts[j]
+			}
+		}
+	}
+
+	return
+}
+
+// healthy indicates session is bound and not rebinding.
+func healthy(s *session) bool {
+	if atomic.LoadInt32(&s.state) != 0 {
+		return false
+	}
+
+	var ctx context.Context
+	switch t := s.current().(type) {
+	case *transceiver:
+		ctx = t.out.ctx
+
+	case *receiver:
+		ctx = t.ctx
+
+	default:
+		return false
+	}
+
+	return ctx.Err() == nil
+}
+
+// inflight returns number of outstanding requests of bound Transmitter/Transceiver.
+func inflight(r io.Closer) int {
+	if t, ok := r.(*transceiver); ok {
+		return t.out.pending.inflight()
+	}
+	return 0
+}
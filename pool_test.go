@@ -0,0 +1,215 @@
+package gosmpp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	auth := Auth{SMSC: server.Addr(), SystemID: "pool", Password: "pool"}
+
+	t.Run("roundRobin", func(t *testing.T) {
+		server.Reset()
+
+		var responses int32
+		pool, err := NewPool(NonTLSDialer, auth, pdu.Transceiver, PoolSettings{
+			Size:     5,
+			MaxBinds: 3,
+			Settings: TransceiveSettings{
+				EnquireLink: 200 * time.Millisecond,
+				OnPDU: func(p pdu.PDU, _ bool) {
+					if _, ok := p.(*pdu.SubmitSMResp); ok {
+						atomic.AddInt32(&responses, 1)
+					}
+				},
+			},
+		})
+		require.Nil(t, err)
+		defer func() {
+			_ = pool.Close()
+		}()
+
+		require.Equal(t, 3, pool.Size())
+		require.Equal(t, 3, pool.Healthy())
+		require.Len(t, server.Server().Sessions(), 3)
+
+		// binds are picked in turn
+		first := pool.candidates()[0]
+		require.NotEqual(t, first, pool.candidates()[0])
+
+		for i := 0; i < 6; i++ {
+			require.Nil(t, pool.Submit(newSubmitSM(auth.SystemID)))
+		}
+		_, err = server.Wait(data.SUBMIT_SM, 6, time.Second)
+		require.Nil(t, err)
+
+		// responses of all binds are merged
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&responses) == 6
+		}, time.Second, 10*time.Millisecond)
+
+		// broken bind is skipped
+		_ = pool.binds[0].current().(*transceiver).conn.Close()
+		require.Eventually(t, func() bool {
+			return pool.Healthy() == 2
+		}, time.Second, 10*time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			resp, err := pool.SubmitAndWait(context.Background(), newSubmitSM(auth.SystemID))
+			require.Nil(t, err)
+			require.True(t, resp.IsOk())
+		}
+
+		_ = pool.Close()
+		require.Equal(t, ErrPoolClosed, pool.Submit(newSubmitSM(auth.SystemID)))
+	})
+
+	t.Run("leastInFlight", func(t *testing.T) {
+		server.Reset()
+
+		pool, err := NewPool(NonTLSDialer, auth, pdu.Transmitter, PoolSettings{
+			Size:     2,
+			Balancer: LeastInFlight,
+			Settings: TransceiveSettings{
+				EnquireLink: 200 * time.Millisecond,
+			},
+		})
+		require.Nil(t, err)
+		defer func() {
+			_ = pool.Close()
+		}()
+
+		// the first request is outstanding for a while
+		server.ReplyNext(smpptest.Reply{Delay: 300 * time.Millisecond})
+		require.Nil(t, pool.Submit(newSubmitSM(auth.SystemID)))
+		_, err = server.Wait(data.SUBMIT_SM, 1, time.Second)
+		require.Nil(t, err)
+
+		var busy *session
+		for _, s := range pool.binds {
+			if inflight(s.current()) == 1 {
+				busy = s
+			}
+		}
+		require.NotNil(t, busy)
+
+		for i := 0; i < 4; i++ {
+			require.NotEqual(t, busy, pool.candidates()[0])
+		}
+	})
+
+	t.Run("windowFull", func(t *testing.T) {
+		server.Reset()
+
+		pool, err := NewPool(NonTLSDialer, auth, pdu.Transmitter, PoolSettings{
+			Size: 2,
+			Settings: TransceiveSettings{
+				EnquireLink: 200 * time.Millisecond,
+				WindowSize:  1,
+			},
+		})
+		require.Nil(t, err)
+		defer func() {
+			_ = pool.Close()
+		}()
+
+		server.ReplyNext(smpptest.Reply{Delay: 300 * time.Millisecond}, smpptest.Reply{Delay: 300 * time.Millisecond})
+		require.Nil(t, pool.Submit(newSubmitSM(auth.SystemID)))
+
+		// the same bind is picked first again, its window is full
+		_ = pool.candidates()
+		require.Nil(t, pool.Submit(newSubmitSM(auth.SystemID)))
+		for _, s := range pool.binds {
+			require.Equal(t, 1, inflight(s.current()))
+		}
+
+		require.Equal(t, ErrWindowFull, pool.Submit(newSubmitSM(auth.SystemID)))
+	})
+
+	t.Run("windowWait", func(t *testing.T) {
+		server.Reset()
+
+		pool, err := NewPool(NonTLSDialer, auth, pdu.Transmitter, PoolSettings{
+			Size: 2,
+			Settings: TransceiveSettings{
+				EnquireLink: 200 * time.Millisecond,
+				WindowSize:  1,
+				WindowWait:  200 * time.Millisecond,
+			},
+		})
+		require.Nil(t, err)
+		defer func() {
+			_ = pool.Close()
+		}()
+
+		server.ReplyNext(smpptest.Reply{Delay: time.Second}, smpptest.Reply{Delay: time.Second})
+		for i := 0; i < 2; i++ {
+			require.Nil(t, pool.Submit(newSubmitSM(auth.SystemID)))
+		}
+
+		// windows of both binds are full, waiting is bounded by WindowWait in total
+		start := time.Now()
+		require.Equal(t, ErrWindowFull, pool.Submit(newSubmitSM(auth.SystemID)))
+		elapsed := time.Since(start)
+		require.True(t, elapsed >= 150*time.Millisecond, elapsed)
+		require.True(t, elapsed < 350*time.Millisecond, elapsed)
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		server.Reset()
+
+		var responses int32
+		pool, err := NewPool(NonTLSDialer, auth, pdu.Transceiver, PoolSettings{
+			Size: 2,
+			Settings: TransceiveSettings{
+				EnquireLink: 200 * time.Millisecond,
+				OnPDU: func(p pdu.PDU, _ bool) {
+					if _, ok := p.(*pdu.SubmitSMResp); ok {
+						atomic.AddInt32(&responses, 1)
+					}
+				},
+			},
+		})
+		require.Nil(t, err)
+
+		server.ReplyNext(smpptest.Reply{Delay: 200 * time.Millisecond}, smpptest.Reply{Delay: 200 * time.Millisecond})
+		for i := 0; i < 2; i++ {
+			require.Nil(t, pool.Submit(newSubmitSM(auth.SystemID)))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		// outstanding requests of all binds are responded before unbinding
+		require.Nil(t, pool.Shutdown(ctx))
+		require.EqualValues(t, 2, atomic.LoadInt32(&responses))
+		require.Len(t, server.ReceivedOf(data.UNBIND), 2)
+		require.Equal(t, ErrPoolClosed, pool.Submit(newSubmitSM(auth.SystemID)))
+		require.Nil(t, pool.Close())
+	})
+
+	t.Run("receiver", func(t *testing.T) {
+		pool, err := NewPool(NonTLSDialer, auth, pdu.Receiver, PoolSettings{Size: 2})
+		require.Nil(t, err)
+		defer func() {
+			_ = pool.Close()
+		}()
+
+		require.Equal(t, 2, pool.Healthy())
+		require.Equal(t, ErrPoolNotTransmitter, pool.Submit(newSubmitSM(auth.SystemID)))
+	})
+}
@@ -0,0 +1,188 @@
+package gosmpp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/linxGnu/gosmpp/errors"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+const (
+	defaultRateLimitBackoffFactor = 0.5
+	defaultRateLimitRecovery      = 5 * time.Second
+)
+
+// RateLimit represents token bucket rate limit for PDU(s) sent to SMSC.
+//
+// Rate is automatically backed off when SMSC responds with ESME_RTHROTTLED or ESME_RMSGQFUL,
+// at most once per RecoveryInterval, and recovered gradually after that.
+type RateLimit struct {
+	// Rate is number of PDU(s) allowed to send per second.
+	//
+	// Zero disables rate limit.
+	Rate float64
+
+	// Burst is maximum number of PDU(s) which could be sent at once.
+	//
+	// Default: 1
+	Burst int
+
+	// LimitAll also applies rate limit to EnquireLink and response PDU(s),
+	// which are exempted by default.
+	LimitAll bool
+
+	// BackoffFactor multiplies current rate when SMSC throttles.
+	// Must be in range (0, 1).
+	//
+	// Default: 0.5
+	BackoffFactor float64
+
+	// MinRate is lower bound of backed off rate.
+	//
+	// Default: 1/10 of Rate.
+	MinRate float64
+
+	// RecoveryInterval is duration without throttling, after which rate is
+	// increased by reverse of BackoffFactor, up to Rate.
+	//
+	// Default: 5 secs
+	RecoveryInterval time.Duration
+}
+
+func (s *RateLimit) normalize() {
+	if s.Burst <= 0 {
+		s.Burst = 1
+	}
+
+	if s.BackoffFactor <= 0 || s.BackoffFactor >= 1 {
+		s.BackoffFactor = defaultRateLimitBackoffFactor
+	}
+
+	if s.MinRate <= 0 || s.MinRate > s.Rate {
+		s.MinRate = s.Rate / 10
+	}
+
+	if s.RecoveryInterval <= 0 {
+		s.RecoveryInterval = defaultRateLimitRecovery
+	}
+}
+
+type rateLimiter struct {
+	lock        sync.Mutex
+	settings    RateLimit
+	rate        float64 // current rate
+	tokens      float64
+	last        time.Time
+	throttledAt time.Time
+	backedOffAt time.Time
+}
+
+func newRateLimiter(settings RateLimit) *rateLimiter {
+	if settings.Rate <= 0 {
+		return nil
+	}
+
+	settings.normalize()
+
+	return &rateLimiter{
+		settings: settings,
+		rate:     settings.Rate,
+		tokens:   float64(settings.Burst),
+		last:     time.Now(),
+	}
+}
+
+// exempt checks if PDU is not limited.
+func (r *rateLimiter) exempt(p pdu.PDU) bool {
+	if r.settings.LimitAll {
+		return false
+	}
+
+	switch p.(type) {
+	case *pdu.EnquireLink:
+		return true
+
+	default:
+		return isResponse(p)
+	}
+}
+
+// reserve takes a token, returns duration to wait before the token is available.
+func (r *rateLimiter) reserve(now time.Time) (delay time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// recover rate gradually
+	if r.rate < r.settings.Rate && now.Sub(r.throttledAt) >= r.settings.RecoveryInterval {
+		if r.rate /= r.settings.BackoffFactor; r.rate > r.settings.Rate {
+			r.rate = r.settings.Rate
+		}
+		r.throttledAt = now
+	}
+
+	// refill
+	if elapsed := now.Sub(r.last); elapsed > 0 {
+		if r.tokens += elapsed.Seconds() * r.rate; r.tokens > float64(r.settings.Burst) {
+			r.tokens = float64(r.settings.Burst)
+		}
+		r.last = now
+	}
+
+	// take token, might be in debt
+	if r.tokens--; r.tokens < 0 {
+		delay = time.Duration(-r.tokens / r.rate * float64(time.Second))
+	}
+
+	return
+}
+
+// wait blocks until PDU is allowed to send or ctx is done.
+func (r *rateLimiter) wait(ctx context.Context, p pdu.PDU) (err error) {
+	if r.exempt(p) {
+		return
+	}
+
+	if delay := r.reserve(time.Now()); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+
+		case <-timer.C:
+		}
+	}
+
+	return
+}
+
+// throttled backs off current rate, at most once per RecoveryInterval. Requests in flight
+// are usually throttled in burst, which should not back off rate straight to MinRate.
+func (r *rateLimiter) throttled(now time.Time) {
+	r.lock.Lock()
+	if now.Sub(r.backedOffAt) >= r.settings.RecoveryInterval {
+		if r.rate *= r.settings.BackoffFactor; r.rate < r.settings.MinRate {
+			r.rate = r.settings.MinRate
+		}
+		r.backedOffAt = now
+	}
+	r.throttledAt = now
+	r.lock.Unlock()
+}
+
+// current returns current rate.
+func (r *rateLimiter) current() (v float64) {
+	r.lock.Lock()
+	v = r.rate
+	r.lock.Unlock()
+	return
+}
+
+// isThrottled checks if response PDU indicates that SMSC is throttling.
+func isThrottled(p pdu.PDU) bool {
+	statusErr, ok := pdu.ResponseError(p).(*errors.StatusError)
+	return ok && statusErr.IsThrottle()
+}
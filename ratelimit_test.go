@@ -0,0 +1,89 @@
+package gosmpp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		require.Nil(t, newRateLimiter(RateLimit{}))
+	})
+
+	t.Run("tokenBucket", func(t *testing.T) {
+		r := newRateLimiter(RateLimit{Rate: 10, Burst: 2})
+		now := r.last
+
+		require.Zero(t, r.reserve(now))
+		require.Zero(t, r.reserve(now))
+		require.Equal(t, 100*time.Millisecond, r.reserve(now))
+
+		// refilled
+		require.Zero(t, r.reserve(now.Add(time.Second)))
+	})
+
+	t.Run("exempt", func(t *testing.T) {
+		r := newRateLimiter(RateLimit{Rate: 10})
+		require.True(t, r.exempt(pdu.NewEnquireLink()))
+		require.True(t, r.exempt(pdu.NewDeliverSMResp()))
+		require.False(t, r.exempt(pdu.NewSubmitSM()))
+
+		r = newRateLimiter(RateLimit{Rate: 10, LimitAll: true})
+		require.False(t, r.exempt(pdu.NewEnquireLink()))
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		r := newRateLimiter(RateLimit{Rate: 20})
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			require.Nil(t, r.wait(context.Background(), pdu.NewSubmitSM()))
+		}
+		require.True(t, time.Since(start) >= 90*time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.Equal(t, context.Canceled, r.wait(ctx, pdu.NewSubmitSM()))
+	})
+
+	t.Run("adaptive", func(t *testing.T) {
+		r := newRateLimiter(RateLimit{Rate: 100, RecoveryInterval: time.Second})
+		now := time.Now()
+
+		resp := pdu.NewSubmitSMResp()
+		require.False(t, isThrottled(resp))
+		resp.(*pdu.SubmitSMResp).CommandStatus = data.ESME_RTHROTTLED
+		require.True(t, isThrottled(resp))
+		require.False(t, isThrottled(pdu.NewSubmitSM()))
+
+		r.throttled(now)
+		require.EqualValues(t, 50, r.current())
+
+		// burst of throttled responses backs off once
+		for i := 0; i < 10; i++ {
+			r.throttled(now.Add(time.Duration(i) * time.Millisecond))
+		}
+		require.EqualValues(t, 50, r.current())
+
+		// sustained throttling backs off once per interval, down to MinRate
+		for i := 1; i <= 3; i++ {
+			now = now.Add(time.Second)
+			r.throttled(now)
+		}
+		require.EqualValues(t, 10, r.current())
+
+		// recover gradually
+		_ = r.reserve(now.Add(time.Second))
+		require.EqualValues(t, 20, r.current())
+		_ = r.reserve(now.Add(1500 * time.Millisecond))
+		require.EqualValues(t, 20, r.current())
+		_ = r.reserve(now.Add(10 * time.Second))
+		require.EqualValues(t, 40, r.current())
+	})
+}
@@ -0,0 +1,291 @@
+package gosmpp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+const (
+	defaultReassembleTTL = time.Minute
+)
+
+var (
+	// ErrNotReassemblable indicates PDU is neither deliver_sm nor data_sm.
+	ErrNotReassemblable = fmt.Errorf("PDU is neither deliver_sm nor data_sm, can not be reassembled")
+
+	// ErrInvalidSegment indicates segment number is out of range of total segments.
+	ErrInvalidSegment = fmt.Errorf("Segment number is out of range")
+)
+
+// ReassembledMessage is complete inbound message, stitched from its segments.
+type ReassembledMessage struct {
+	SourceAddr pdu.Address
+	DestAddr   pdu.Address
+
+	// DataCoding of the first segment, used to decode Message.
+	DataCoding byte
+
+	// Data is concatenated user data, without UDH.
+	Data []byte
+
+	// Message is decoded Data.
+	Message string
+
+	// Parts are PDU(s) which make up the message, ordered by segment number.
+	Parts []pdu.PDU
+}
+
+// ReassembleSettings is settings for Reassembler.
+type ReassembleSettings struct {
+	// TTL is maximum duration to keep incomplete message. Incomplete messages are expired
+	// while handling next PDU(s) or calling Expire.
+	//
+	// Default: 1 minute
+	TTL time.Duration
+
+	// OnMessage receives reassembled messages, when Reassembler is used as PDUCallback.
+	OnMessage MessageCallback
+
+	// OnExpired receives parts of expired incomplete message.
+	OnExpired PartsCallback
+
+	// OnError notifies error while reassembling message, when Reassembler is used as PDUCallback.
+	OnError PDUErrorCallback
+}
+
+type reassembleKey struct {
+	source, dest pdu.Address
+	ref          uint16
+	total        byte
+}
+
+type reassembleGroup struct {
+	created  time.Time
+	coding   byte
+	parts    []pdu.PDU
+	data     [][]byte
+	received int
+}
+
+// Reassembler reassembles concatenated inbound messages (deliver_sm, data_sm).
+// Segments are recognized by UDH IE 0x00 (8-bit reference), IE 0x08 (16-bit reference)
+// or sar_msg_ref_num, sar_total_segments, sar_segment_seqnum TLVs.
+//
+// Reassembler is safe for concurrent use.
+type Reassembler struct {
+	settings ReassembleSettings
+	lock     sync.Mutex
+	groups   map[reassembleKey]*reassembleGroup
+}
+
+// NewReassembler returns new Reassembler.
+func NewReassembler(settings ReassembleSettings) *Reassembler {
+	if settings.TTL <= 0 {
+		settings.TTL = defaultReassembleTTL
+	}
+
+	return &Reassembler{
+		settings: settings,
+		groups:   make(map[reassembleKey]*reassembleGroup),
+	}
+}
+
+// OnPDU returns PDUCallback, which could be set to TransceiveSettings.OnPDU or ReceiveSettings.OnPDU.
+// Messages (except delivery receipts) are reassembled and passed to OnMessage,
+// other PDU(s) are passed to next callback.
+func (r *Reassembler) OnPDU(next PDUCallback) PDUCallback {
+	return func(p pdu.PDU, responded bool) {
+		switch pd := p.(type) {
+		case *pdu.DeliverSM:
+			if !pd.IsDeliveryReceipt() {
+				r.handle(p)
+				return
+			}
+
+		case *pdu.DataSM:
+			r.handle(p)
+			return
+		}
+
+		if next != nil {
+			next(p, responded)
+		}
+	}
+}
+
+func (r *Reassembler) handle(p pdu.PDU) {
+	msg, err := r.Add(p)
+	if err != nil {
+		if r.settings.OnError != nil {
+			r.settings.OnError(p, err)
+		}
+		return
+	}
+
+	if msg != nil && r.settings.OnMessage != nil {
+		r.settings.OnMessage(msg)
+	}
+}
+
+// Add a deliver_sm or data_sm. Returns reassembled message if PDU is not segmented
+// or completes its message, otherwise nil.
+func (r *Reassembler) Add(p pdu.PDU) (msg *ReassembledMessage, err error) {
+	var (
+		source, dest pdu.Address
+		udh          pdu.UDH
+		userData     []byte
+		coding       byte
+		sarInfo      func() (uint16, byte, byte, bool)
+	)
+
+	switch pd := p.(type) {
+	case *pdu.DeliverSM:
+		source, dest, sarInfo = pd.SourceAddr, pd.DestAddr, pd.SarInfo
+		coding = pd.Message.DataCoding()
+
+		// message_payload is loaded into Message while parsing
+		if userData, err = pd.Message.GetMessageData(); err != nil {
+			return
+		}
+		udh = pd.Message.UDH()
+
+	case *pdu.DataSM:
+		source, dest, sarInfo = pd.SourceAddr, pd.DestAddr, pd.SarInfo
+		coding = pd.DataCoding
+
+		var sm pdu.ShortMessage
+		if sm, err = pd.PayloadMessage(); err != nil {
+			return
+		}
+		if userData, err = sm.GetMessageData(); err != nil {
+			return
+		}
+		udh = sm.UDH()
+
+	default:
+		err = ErrNotReassemblable
+		return
+	}
+
+	r.Expire(time.Now())
+
+	total, seq, ref, found := udh.GetConcatInfo16()
+	if !found {
+		ref, total, seq, found = sarInfo()
+	}
+
+	if !found || total <= 1 {
+		return newReassembledMessage(source, dest, coding, [][]byte{userData}, []pdu.PDU{p})
+	}
+
+	if seq == 0 || seq > total {
+		err = ErrInvalidSegment
+		return
+	}
+
+	key := reassembleKey{source: source, dest: dest, ref: ref, total: total}
+
+	r.lock.Lock()
+	g, ok := r.groups[key]
+	if !ok {
+		g = &reassembleGroup{
+			created: time.Now(),
+			parts:   make([]pdu.PDU, total),
+			data:    make([][]byte, total),
+		}
+		r.groups[key] = g
+	}
+
+	// duplicated segment replaces previous one
+	if g.parts[seq-1] == nil {
+		g.received++
+	}
+	g.parts[seq-1], g.data[seq-1] = p, userData
+
+	// message is decoded with data coding of the first segment
+	if seq == 1 {
+		g.coding = coding
+	}
+
+	complete := g.received == int(total)
+	if complete {
+		delete(r.groups, key)
+	}
+	r.lock.Unlock()
+
+	if complete {
+		return newReassembledMessage(source, dest, g.coding, g.data, g.parts)
+	}
+
+	return
+}
+
+// Expire incomplete messages which are older than TTL.
+func (r *Reassembler) Expire(now time.Time) {
+	var expired [][]pdu.PDU
+
+	r.lock.Lock()
+	for key, g := range r.groups {
+		if now.Sub(g.created) >= r.settings.TTL {
+			delete(r.groups, key)
+			expired = append(expired, g.parts)
+		}
+	}
+	r.lock.Unlock()
+
+	if r.settings.OnExpired != nil {
+		for _, parts := range expired {
+			received := make([]pdu.PDU, 0, len(parts))
+			for _, p := range parts {
+				if p != nil {
+					received = append(received, p)
+				}
+			}
+			r.settings.OnExpired(received)
+		}
+	}
+}
+
+// Len returns number of incomplete messages.
+func (r *Reassembler) Len() (n int) {
+	r.lock.Lock()
+	n = len(r.groups)
+	r.lock.Unlock()
+	return
+}
+
+func newReassembledMessage(source, dest pdu.Address, coding byte, segments [][]byte, parts []pdu.PDU) (msg *ReassembledMessage, err error) {
+	size := 0
+	for _, segment := range segments {
+		size += len(segment)
+	}
+
+	userData := make([]byte, 0, size)
+	for _, segment := range segments {
+		userData = append(userData, segment...)
+	}
+
+	enc := data.FromDataCoding(coding)
+	if enc == nil {
+		enc = data.GSM7BIT
+	}
+
+	var message string
+	if message, err = enc.Decode(userData); err != nil {
+		return
+	}
+
+	msg = &ReassembledMessage{
+		SourceAddr: source,
+		DestAddr:   dest,
+		DataCoding: coding,
+		Data:       userData,
+		Message:    message,
+		Parts:      parts,
+	}
+	return
+}
@@ -0,0 +1,164 @@
+package gosmpp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+
+	"github.com/stretchr/testify/require"
+)
+
+// inbound marshals and parses PDU back, as it is received from SMSC.
+func inbound(t *testing.T, p pdu.PDU) pdu.PDU {
+	buf := pdu.NewBuffer(nil)
+	p.Marshal(buf)
+
+	parsed, err := pdu.Parse(buf)
+	require.Nil(t, err)
+	return parsed
+}
+
+func newSegment(t *testing.T, source string, udh pdu.UDH, segment []byte) pdu.PDU {
+	d := pdu.NewDeliverSM().(*pdu.DeliverSM)
+	_ = d.SourceAddr.SetAddress(source)
+	d.EsmClass = data.SM_UDH_GSM
+	d.Message.SetDataCoding(data.UCS2Coding)
+	d.Message.SetUDH(udh)
+	d.Message.SetMessageData(segment)
+	return inbound(t, d)
+}
+
+func TestReassembler(t *testing.T) {
+	t.Run("udh8bit", func(t *testing.T) {
+		long := strings.Repeat("Thử nghiệm: chuẩn bị nế mễ ", 8)
+
+		var sm pdu.ShortMessage
+		require.Nil(t, sm.SetLongMessageWithEnc(long, data.UCS2))
+		segments, err := sm.Split()
+		require.Nil(t, err)
+		require.True(t, len(segments) > 2)
+
+		r := NewReassembler(ReassembleSettings{})
+		for i := len(segments) - 1; i >= 0; i-- {
+			d := pdu.NewDeliverSM().(*pdu.DeliverSM)
+			_ = d.SourceAddr.SetAddress("alice")
+			d.EsmClass = data.SM_UDH_GSM
+			d.Message = *segments[i]
+
+			msg, err := r.Add(inbound(t, d))
+			require.Nil(t, err)
+			if i > 0 {
+				require.Nil(t, msg)
+				require.Equal(t, 1, r.Len())
+			} else {
+				require.NotNil(t, msg)
+				require.Equal(t, long, msg.Message)
+				require.Equal(t, "alice", msg.SourceAddr.Address())
+				require.Len(t, msg.Parts, len(segments))
+			}
+		}
+		require.Zero(t, r.Len())
+	})
+
+	t.Run("udh16bit", func(t *testing.T) {
+		r := NewReassembler(ReassembleSettings{})
+
+		ie := func(seq byte) pdu.UDH {
+			return pdu.UDH{{ID: data.UDH_CONCAT_MSG_16_BIT_REF, Data: []byte{0x12, 0x34, 2, seq}}}
+		}
+
+		// same reference from other source does not interfere
+		msg, err := r.Add(newSegment(t, "bob", ie(1), []byte{0x00, 0x58}))
+		require.Nil(t, err)
+		require.Nil(t, msg)
+
+		msg, err = r.Add(newSegment(t, "alice", ie(2), []byte{0x00, 0x42}))
+		require.Nil(t, err)
+		require.Nil(t, msg)
+
+		msg, err = r.Add(newSegment(t, "alice", ie(1), []byte{0x00, 0x41}))
+		require.Nil(t, err)
+		require.Equal(t, "AB", msg.Message)
+		require.Equal(t, 1, r.Len())
+
+		_, err = r.Add(newSegment(t, "alice", ie(3), []byte{0x00, 0x41}))
+		require.Equal(t, ErrInvalidSegment, err)
+	})
+
+	t.Run("sar", func(t *testing.T) {
+		r := NewReassembler(ReassembleSettings{})
+
+		segment := func(seq byte, text string) pdu.PDU {
+			d := pdu.NewDataSM().(*pdu.DataSM)
+			_ = d.SourceAddr.SetAddress("alice")
+			d.RegisterOptionalParam(pdu.Field{Tag: pdu.TagMessagePayload, Data: []byte(text)})
+			d.SetSarInfo(0x0102, 2, seq)
+			return inbound(t, d)
+		}
+
+		msg, err := r.Add(segment(2, "world"))
+		require.Nil(t, err)
+		require.Nil(t, msg)
+
+		msg, err = r.Add(segment(1, "hello "))
+		require.Nil(t, err)
+		require.Equal(t, "hello world", msg.Message)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		var expired []pdu.PDU
+		r := NewReassembler(ReassembleSettings{
+			TTL: 50 * time.Millisecond,
+			OnExpired: func(parts []pdu.PDU) {
+				expired = parts
+			},
+		})
+
+		ie := pdu.UDH{pdu.NewIEConcatMessage(2, 1, 7)}
+		msg, err := r.Add(newSegment(t, "alice", ie, []byte{0x00, 0x41}))
+		require.Nil(t, err)
+		require.Nil(t, msg)
+
+		r.Expire(time.Now())
+		require.Equal(t, 1, r.Len())
+
+		r.Expire(time.Now().Add(time.Second))
+		require.Zero(t, r.Len())
+		require.Len(t, expired, 1)
+	})
+
+	t.Run("onPDU", func(t *testing.T) {
+		var (
+			messages []*ReassembledMessage
+			others   []pdu.PDU
+		)
+		r := NewReassembler(ReassembleSettings{
+			OnMessage: func(m *ReassembledMessage) {
+				messages = append(messages, m)
+			},
+		})
+		handler := r.OnPDU(func(p pdu.PDU, responded bool) {
+			others = append(others, p)
+		})
+
+		single := pdu.NewDeliverSM().(*pdu.DeliverSM)
+		_ = single.Message.SetMessageWithEncoding("single", data.GSM7BIT)
+		handler(inbound(t, single), true)
+
+		receipt := pdu.NewDeliverSM().(*pdu.DeliverSM)
+		receipt.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE
+		handler(inbound(t, receipt), true)
+
+		handler(pdu.NewSubmitSMResp(), false)
+
+		require.Len(t, messages, 1)
+		require.Equal(t, "single", messages[0].Message)
+		require.Len(t, others, 2)
+
+		_, err := r.Add(pdu.NewSubmitSMResp())
+		require.Equal(t, ErrNotReassemblable, err)
+	})
+}
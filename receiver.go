@@ -2,24 +2,52 @@ package gosmpp
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/linxGnu/gosmpp/errors"
 	"github.com/linxGnu/gosmpp/pdu"
 )
 
 const (
 	defaultReadTimeout = 2 * time.Second
+	defaultIdleTimeout = time.Minute
+)
+
+var (
+	// ErrEnquireLinkTimeout indicates enquire_link(s) are not responded by SMSC in time, hence link is dead.
+	ErrEnquireLinkTimeout = fmt.Errorf("Enquire link is not responded by SMSC in time. Link is dead")
+
+	// ErrReceiverClosed indicates receiver is closed while unbinding.
+	ErrReceiverClosed = fmt.Errorf("Receiver is closed")
 )
 
 // ReceiveSettings is event listener for Receiver.
 type ReceiveSettings struct {
-	// Timeout represents conn read timeout.
-	// This field is very important to detect connection failure.
+	// Timeout represents conn read timeout for reading PDU from SMSC.
 	// Default: 2 secs
 	Timeout time.Duration
 
+	// IdleTimeout is maximum duration that nothing is read from SMSC. When it elapses,
+	// enquire_link is sent to check if link is still alive.
+	//
+	// Default: 1 minute
+	IdleTimeout time.Duration
+
+	// EnquireLinkTimeout is maximum duration to wait for enquire_link_resp.
+	// Enquire link which is not responded in time is counted as missed.
+	//
+	// Default: 5 secs
+	EnquireLinkTimeout time.Duration
+
+	// EnquireLinkMaxMissed is number of consecutive missed enquire_link(s),
+	// after which link is considered dead and closed with ConnectionIssue.
+	//
+	// Default: 3
+	EnquireLinkMaxMissed int
+
 	// OnPDU handles received PDU from SMSC.
 	//
 	// `Responded` flag indicates this pdu is responded automatically,
@@ -30,32 +58,70 @@ type ReceiveSettings struct {
 	// from SMSC.
 	OnReceivingError ErrorCallback
 
+	// OnRequest handles received requests (deliver_sm, data_sm, ...) which are responded
+	// manually, through Responder. If set, requests are neither responded automatically nor
+	// passed to OnPDU. EnquireLink, Unbind and PDU(s) with unknown command id are always
+	// responded automatically.
+	OnRequest RequestCallback
+
 	// OnRebindingError notifies error while rebinding.
 	OnRebindingError ErrorCallback
 
+	// RebindingBackoff decides delays between rebinding attempts of session.
+	//
+	// Default: ConstantBackoff with rebinding interval of session.
+	RebindingBackoff BackoffPolicy
+
+	// OnRebindingGaveUp notifies session gave up rebinding, as RebindingBackoff decided.
+	// Session is closed afterwards.
+	OnRebindingGaveUp GiveUpCallback
+
 	// OnClosed notifies `closed` event due to State.
 	OnClosed ClosedCallback
 
-	response func(pdu.PDU)
+	response    func(pdu.PDU) error
+	enquireLink func()
 }
 
 func (s *ReceiveSettings) normalize() {
 	if s.Timeout <= 0 {
 		s.Timeout = defaultReadTimeout
 	}
+
+	if s.IdleTimeout <= 0 {
+		s.IdleTimeout = defaultIdleTimeout
+	}
+}
+
+// session returns settings shared with session.
+func (s *ReceiveSettings) session() sessionSettings {
+	return sessionSettings{
+		RebindingBackoff:  s.RebindingBackoff,
+		OnRebindingError:  s.OnRebindingError,
+		OnRebindingGaveUp: s.OnRebindingGaveUp,
+		OnClosed:          s.OnClosed,
+	}
 }
 
 type receiver struct {
-	ctx      context.Context
-	cancel   context.CancelFunc
-	wg       sync.WaitGroup
-	settings ReceiveSettings
-	conn     *Connection
-	state    int32
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	settings  ReceiveSettings
+	conn      *Connection
+	liveness  *liveness
+	unbinding int32
+	unbound   chan struct{}
+	state     int32
 }
 
 // NewReceiver returns new Receiver, bound with inputStream stream.
+// Received requests are responded through the same connection.
 func NewReceiver(conn *Connection, settings ReceiveSettings) Receiver {
+	settings.response = func(p pdu.PDU) (err error) {
+		_, err = conn.Write(marshal(p))
+		return
+	}
 	return newReceiver(conn, settings, true)
 }
 
@@ -65,6 +131,8 @@ func newReceiver(conn *Connection, settings ReceiveSettings, startDaemon bool) *
 	r := &receiver{
 		settings: settings,
 		conn:     conn,
+		liveness: newLiveness(settings.EnquireLinkTimeout, settings.EnquireLinkMaxMissed),
+		unbound:  make(chan struct{}),
 	}
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 
@@ -86,6 +154,32 @@ func (t *receiver) Close() (err error) {
 	return t.close(ExplicitClosing)
 }
 
+// Shutdown receiver gracefully: unbinds from SMSC, waits for unbind_resp, then closes.
+// Receiver is closed immediately once ctx is done.
+func (t *receiver) Shutdown(ctx context.Context) (err error) {
+	if atomic.CompareAndSwapInt32(&t.unbinding, 0, 1) {
+		p := pdu.NewUnbind()
+		t.conn.assignSequenceNumber(p)
+
+		if _, err = t.conn.Write(marshal(p)); err == nil {
+			select {
+			case <-t.unbound:
+
+			case <-t.ctx.Done():
+				err = ErrReceiverClosed
+
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+	}
+
+	if e := t.Close(); err == nil {
+		err = e
+	}
+	return
+}
+
 func (t *receiver) close(state State) (err error) {
 	if atomic.CompareAndSwapInt32(&t.state, 0, 1) {
 		// cancel to notify stop
@@ -147,11 +241,44 @@ func (t *receiver) loop() {
 		default:
 		}
 
+		if t.liveness.dead(time.Now()) {
+			_ = t.check(ErrEnquireLinkTimeout)
+			t.closing(ConnectionIssue)
+			return
+		}
+
+		// wait for incoming data, idle connection is checked with enquire_link
+		err := t.conn.SetReadTimeout(t.idle())
+		if err == nil {
+			if _, err = t.conn.reader.Peek(1); isTimeout(err) {
+				if err = t.enquireLink(); err == nil {
+					continue
+				}
+			}
+		}
+
 		// read pdu from conn
 		var p pdu.PDU
-		err := t.conn.SetReadTimeout(t.settings.Timeout)
 		if err == nil {
-			p, err = pdu.Parse(t.conn)
+			if err = t.conn.SetReadTimeout(t.settings.Timeout); err == nil {
+				p, err = pdu.Parse(t.conn)
+			}
+		}
+
+		// PDU with invalid TLV is handled as usual, after error is notified
+		if _, ok := err.(*pdu.InvalidTLVError); ok && p != nil {
+			if t.settings.OnReceivingError != nil {
+				t.settings.OnReceivingError(err)
+			}
+			err = nil
+		}
+
+		// PDU with unknown command id is handled as usual, responded with generic_nack
+		if err == errors.ErrUnknownCommandID && p != nil {
+			if t.settings.OnReceivingError != nil {
+				t.settings.OnReceivingError(err)
+			}
+			err = nil
 		}
 
 		// check error
@@ -164,29 +291,85 @@ func (t *receiver) loop() {
 	}
 }
 
+// idle returns duration to wait for incoming data. Enquire link waiting for response
+// is checked once it is expired, and link which missed one is checked again right away.
+func (t *receiver) idle() time.Duration {
+	switch {
+	case t.liveness.waiting():
+		if t.liveness.timeout < t.settings.IdleTimeout {
+			return t.liveness.timeout
+		}
+
+	case t.liveness.missing():
+		return 0
+	}
+	return t.settings.IdleTimeout
+}
+
+// enquireLink checks idle link, unless there is one waiting for response already.
+func (t *receiver) enquireLink() (err error) {
+	if t.ctx.Err() != nil || t.liveness.waiting() {
+		return
+	}
+
+	// sent by transmitter half of transceiver
+	if t.settings.enquireLink != nil {
+		t.settings.enquireLink()
+		return
+	}
+
+	p := pdu.NewEnquireLink()
+	t.conn.assignSequenceNumber(p)
+	t.liveness.sent(p.GetSequenceNumber(), time.Now())
+
+	_, err = t.conn.Write(marshal(p))
+	return
+}
+
 func (t *receiver) handleOrClose(p pdu.PDU) (closing bool) {
 	if p != nil {
+		if resp, ok := p.(*pdu.EnquireLinkResp); ok {
+			t.liveness.responded(resp.SequenceNumber)
+		}
+
 		switch pp := p.(type) {
 		case *pdu.EnquireLink:
 			if t.settings.response != nil {
-				t.settings.response(pp.GetResponse())
+				_ = t.settings.response(pp.GetResponse())
 			}
 
 		case *pdu.Unbind:
+			// response is sent before closing
 			if t.settings.response != nil {
-				t.settings.response(pp.GetResponse())
-
-				// wait to send response before closing
-				time.Sleep(50 * time.Millisecond)
+				_ = t.settings.response(pp.GetResponse())
 			}
 
 			closing = true
 			t.closing(UnbindClosing)
 
+		case *pdu.UnbindResp:
+			if t.settings.OnPDU != nil {
+				t.settings.OnPDU(p, false)
+			}
+
+			// SMSC confirmed graceful unbinding, nothing to read anymore
+			if atomic.LoadInt32(&t.unbinding) != 0 {
+				closing = true
+				close(t.unbound)
+			}
+
 		default:
+			canResponse := p.CanResponse() && t.settings.response != nil
+
+			// PDU with unknown command id is always nacked, never responded manually
+			if _, unknown := p.(*pdu.UnknownPDU); !unknown && canResponse && t.settings.OnRequest != nil {
+				t.settings.OnRequest(p, &Responder{request: p, send: t.settings.response})
+				return
+			}
+
 			var responded bool
-			if p.CanResponse() && t.settings.response != nil {
-				t.settings.response(p.GetResponse())
+			if canResponse {
+				_ = t.settings.response(p.GetResponse())
 				responded = true
 			}
 
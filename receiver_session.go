@@ -1,24 +1,18 @@
 package gosmpp
 
 import (
-	"sync/atomic"
+	"context"
+	"io"
 	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
 )
 
 // ReceiverSession represents session for Receiver.
 type ReceiverSession struct {
-	dialer Dialer
-	auth   Auth
-
-	originalOnClosed func(State)
-	settings         ReceiveSettings
-
-	rebindingInterval time.Duration
+	*session
 
-	r atomic.Value // Receiver
-
-	state     int32
-	rebinding int32
+	settings ReceiveSettings
 }
 
 // NewReceiverSession creates new session for Receiver.
@@ -27,42 +21,27 @@ type ReceiverSession struct {
 // unexpected error happened.
 //
 // `rebindingInterval` indicates duration that Session has to wait before rebinding again.
+// Settings.RebindingBackoff, if set, takes precedence over it.
 //
-// Setting `rebindingInterval <= 0` will disable `auto-rebind` functionality.
+// Setting `rebindingInterval <= 0` without RebindingBackoff will disable `auto-rebind` functionality.
 func NewReceiverSession(dialer Dialer, auth Auth, settings ReceiveSettings, rebindingInterval time.Duration) (session *ReceiverSession, err error) {
-	conn, err := ConnectAsReceiver(dialer, auth)
-	if err == nil {
-		session = &ReceiverSession{
-			dialer:            dialer,
-			auth:              auth,
-			rebindingInterval: rebindingInterval,
-			originalOnClosed:  settings.OnClosed,
-		}
-
-		if rebindingInterval > 0 {
-			newSettings := settings
-			newSettings.OnClosed = func(state State) {
-				switch state {
-				case ExplicitClosing:
-					return
-
-				default:
-					if session.originalOnClosed != nil {
-						session.originalOnClosed(state)
-					}
-					session.rebind()
-				}
-			}
-			session.settings = newSettings
-		} else {
-			session.settings = settings
-		}
+	return NewReceiverSessionContext(context.Background(), dialer, auth, settings, rebindingInterval)
+}
 
-		// create new receiver
-		r := NewReceiver(conn, session.settings)
+// NewReceiverSessionContext creates new session for Receiver, like NewReceiverSession.
+// Dialing and binding of the first connection are canceled once context is done.
+func NewReceiverSessionContext(ctx context.Context, dialer Dialer, auth Auth, settings ReceiveSettings, rebindingInterval time.Duration) (session *ReceiverSession, err error) {
+	session = &ReceiverSession{
+		session:  newSession(dialer, auth, pdu.Receiver, settings.session(), rebindingInterval),
+		settings: settings,
+	}
+	session.settings.OnClosed = session.closed
 
-		// bind to session
-		session.r.Store(r)
+	// create new receiver
+	if err = session.start(ctx, func(conn *Connection) io.Closer {
+		return NewReceiver(conn, session.settings)
+	}); err != nil {
+		session = nil
 	}
 	return
 }
@@ -73,46 +52,23 @@ func (s *ReceiverSession) Receiver() (r Receiver) {
 	return
 }
 
-// Close session.
-func (s *ReceiverSession) Close() (err error) {
-	if atomic.CompareAndSwapInt32(&s.state, 0, 1) {
-		// close underlying Receiver
-		err = s.close()
-	}
+// Endpoint returns SMSC address which session is bound to.
+func (s *ReceiverSession) Endpoint() (endpoint string) {
+	endpoint, _ = s.endpoint.Load().(string)
 	return
 }
 
-// close underlying Receiver
-func (s *ReceiverSession) close() (err error) {
-	if r := s.Receiver(); r != nil {
-		err = r.Close()
-	}
-	return
+// RebindAttempts returns total number of rebinding attempts made by session.
+func (s *ReceiverSession) RebindAttempts() uint64 {
+	return s.rebinder.total()
 }
 
-func (s *ReceiverSession) rebind() {
-	if atomic.CompareAndSwapInt32(&s.rebinding, 0, 1) {
-		// close underlying Receiver
-		_ = s.close()
-
-		for atomic.LoadInt32(&s.state) == 0 {
-			conn, err := ConnectAsReceiver(s.dialer, s.auth)
-			if err != nil {
-				if s.settings.OnRebindingError != nil {
-					s.settings.OnRebindingError(err)
-				}
-				time.Sleep(s.rebindingInterval)
-			} else {
-				r := NewReceiver(conn, s.settings)
-
-				// bind to session
-				s.r.Store(r)
-
-				// reset rebinding state
-				atomic.StoreInt32(&s.rebinding, 0)
+// Close session.
+func (s *ReceiverSession) Close() error {
+	return s.session.Close()
+}
 
-				return
-			}
-		}
-	}
+// Shutdown session gracefully, stopping rebinding. See Receiver.Shutdown.
+func (s *ReceiverSession) Shutdown(ctx context.Context) error {
+	return s.session.Shutdown(ctx)
 }
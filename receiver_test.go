@@ -2,10 +2,14 @@ package gosmpp
 
 import (
 	"fmt"
+	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/linxGnu/gosmpp/data"
 	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -32,8 +36,237 @@ func TestReceiver(t *testing.T) {
 		_ = receiver.Close()
 	}()
 
-	require.Equal(t, "MelroseLabsSMSC", receiver.Receiver().SystemID())
+	require.Equal(t, smscSystemID, receiver.Receiver().SystemID())
 
 	time.Sleep(time.Second)
 	receiver.rebind()
 }
+
+func TestReceiverManualResponse(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var (
+		pdus       int32
+		responders = make(chan *Responder, 2)
+	)
+
+	auth := Auth{SMSC: server.Addr(), SystemID: "manual", Password: "manual"}
+	receiver, err := NewReceiverSession(NonTLSDialer, auth, ReceiveSettings{
+		OnPDU: func(p pdu.PDU, responded bool) {
+			atomic.AddInt32(&pdus, 1)
+		},
+		OnRequest: func(p pdu.PDU, r *Responder) {
+			if _, ok := p.(*pdu.DeliverSM); ok {
+				// failed to store, ask SMSC to retry
+				require.Nil(t, r.RespondStatus(data.ESME_RX_T_APPN))
+				require.Equal(t, ErrAlreadyResponded, r.RespondStatus(data.ESME_ROK))
+				return
+			}
+
+			// respond later
+			responders <- r
+		},
+	}, 0)
+	require.Nil(t, err)
+	defer func() {
+		_ = receiver.Close()
+	}()
+
+	require.Nil(t, server.Deliver(auth.SystemID, pdu.NewDeliverSM()))
+	resps, err := server.Wait(data.DELIVER_SM_RESP, 1, time.Second)
+	require.Nil(t, err)
+	require.Equal(t, data.ESME_RX_T_APPN, resps[0].GetHeader().CommandStatus)
+
+	require.Nil(t, server.Deliver(auth.SystemID, pdu.NewDataSM()))
+	r := <-responders
+
+	resp := r.Request().GetResponse().(*pdu.DataSMResp)
+	resp.MessageID = "stored-1"
+	require.Nil(t, r.Respond(resp))
+
+	resps, err = server.Wait(data.DATA_SM_RESP, 1, time.Second)
+	require.Nil(t, err)
+	require.Equal(t, "stored-1", resps[0].(*pdu.DataSMResp).MessageID)
+	require.Equal(t, r.Request().GetSequenceNumber(), resps[0].GetSequenceNumber())
+
+	// requests are not passed to OnPDU
+	require.Zero(t, atomic.LoadInt32(&pdus))
+}
+
+func TestResponder(t *testing.T) {
+	var sent []pdu.PDU
+	failing := true
+
+	req := pdu.NewDeliverSM()
+	r := &Responder{request: req, send: func(p pdu.PDU) error {
+		if failing {
+			return ErrTransmitterClosing
+		}
+		sent = append(sent, p)
+		return nil
+	}}
+
+	// failed response could be retried
+	require.Equal(t, ErrTransmitterClosing, r.RespondStatus(data.ESME_ROK))
+
+	failing = false
+	require.Nil(t, r.RespondStatus(data.ESME_ROK))
+	require.Equal(t, ErrAlreadyResponded, r.RespondStatus(data.ESME_ROK))
+
+	require.Len(t, sent, 1)
+	require.Equal(t, req.GetSequenceNumber(), sent[0].GetSequenceNumber())
+}
+
+func TestReceiverUnknownCommandID(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var (
+		unknown    int32
+		receivings int32
+	)
+
+	auth := Auth{SMSC: server.Addr(), SystemID: "unknown", Password: "unknown"}
+	receiver, err := NewReceiverSession(NonTLSDialer, auth, ReceiveSettings{
+		OnPDU: func(p pdu.PDU, responded bool) {
+			if pd, ok := p.(*pdu.UnknownPDU); ok && responded {
+				require.Equal(t, []byte{0x01, 0x02}, pd.Body)
+				atomic.AddInt32(&unknown, 1)
+			}
+		},
+		OnReceivingError: func(err error) {
+			atomic.AddInt32(&receivings, 1)
+		},
+	}, 0)
+	require.Nil(t, err)
+	defer func() {
+		_ = receiver.Close()
+	}()
+
+	// vendor-specific PDU is nacked
+	require.Nil(t, server.Deliver(auth.SystemID, pdu.NewUnknownPDU(0x00010200, []byte{0x01, 0x02})))
+	resps, err := server.Wait(data.GENERIC_NACK, 1, time.Second)
+	require.Nil(t, err)
+	require.Equal(t, data.ESME_RINVCMDID, resps[0].GetHeader().CommandStatus)
+	require.EqualValues(t, 1, atomic.LoadInt32(&receivings))
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&unknown) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// bind is kept
+	require.Nil(t, server.Deliver(auth.SystemID, pdu.NewDeliverSM()))
+	_, err = server.Wait(data.DELIVER_SM_RESP, 1, time.Second)
+	require.Nil(t, err)
+}
+
+func TestReceiverUnknownCommandIDManualResponse(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var (
+		unknown  int32
+		requests int32
+	)
+
+	auth := Auth{SMSC: server.Addr(), SystemID: "manual", Password: "manual"}
+	receiver, err := NewReceiverSession(NonTLSDialer, auth, ReceiveSettings{
+		OnPDU: func(p pdu.PDU, responded bool) {
+			if _, ok := p.(*pdu.UnknownPDU); ok && responded {
+				atomic.AddInt32(&unknown, 1)
+			}
+		},
+		OnRequest: func(p pdu.PDU, r *Responder) {
+			atomic.AddInt32(&requests, 1)
+			_ = r.RespondStatus(data.ESME_ROK)
+		},
+	}, 0)
+	require.Nil(t, err)
+	defer func() {
+		_ = receiver.Close()
+	}()
+
+	// vendor-specific PDU is nacked, even though requests are responded manually
+	require.Nil(t, server.Deliver(auth.SystemID, pdu.NewUnknownPDU(0x00010200, []byte{0x01, 0x02})))
+	resps, err := server.Wait(data.GENERIC_NACK, 1, time.Second)
+	require.Nil(t, err)
+	require.Equal(t, data.ESME_RINVCMDID, resps[0].GetHeader().CommandStatus)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&unknown) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// known requests still go to OnRequest
+	require.Nil(t, server.Deliver(auth.SystemID, pdu.NewDeliverSM()))
+	_, err = server.Wait(data.DELIVER_SM_RESP, 1, time.Second)
+	require.Nil(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&requests))
+}
+
+func TestReceiverInvalidTLV(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var (
+		delivered  = make(chan pdu.PDU, 2)
+		receivings int32
+	)
+
+	trans := NewTransceiver(NewConnection(client), TransceiveSettings{
+		OnPDU: func(p pdu.PDU, responded bool) {
+			if responded {
+				delivered <- p
+			}
+		},
+		OnReceivingError: func(err error) {
+			if _, ok := err.(*pdu.InvalidTLVError); ok {
+				atomic.AddInt32(&receivings, 1)
+			}
+		},
+	})
+	defer func() {
+		go fakeSMSC(server, func(p pdu.PDU) pdu.PDU {
+			return p.GetResponse()
+		})
+		_ = trans.Close()
+	}()
+
+	deliver := func(tlv pdu.Field) pdu.PDU {
+		p := pdu.NewDeliverSM()
+		p.RegisterOptionalParam(tlv)
+		_, err := server.Write(marshal(p))
+		require.Nil(t, err)
+
+		resp, err := pdu.Parse(server)
+		require.Nil(t, err)
+		require.EqualValues(t, data.ESME_ROK, resp.GetHeader().CommandStatus)
+
+		select {
+		case p = <-delivered:
+		case <-time.After(time.Second):
+			t.Fatal("deliver_sm is not passed to OnPDU")
+		}
+		return p
+	}
+
+	// receipted_message_id without null terminator is accepted
+	p := deliver(pdu.Field{Tag: pdu.TagReceiptedMessageID, Data: []byte("msg-1")})
+	id, ok := p.(*pdu.DeliverSM).ReceiptedMessageID()
+	require.True(t, ok)
+	require.Equal(t, "msg-1", id)
+	require.Zero(t, atomic.LoadInt32(&receivings))
+
+	// invalid TLV is notified, PDU is handled as usual
+	deliver(pdu.Field{Tag: pdu.TagSmsSignal, Data: []byte{1, 2, 3}})
+	require.EqualValues(t, 1, atomic.LoadInt32(&receivings))
+}
@@ -0,0 +1,261 @@
+package gosmpp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrGenericNack indicates SMSC responded to request with generic_nack.
+	ErrGenericNack = fmt.Errorf("SMSC responded with generic_nack")
+
+	// ErrNoResponse indicates submitted PDU does not expect any response from SMSC.
+	ErrNoResponse = fmt.Errorf("PDU does not expect any response")
+
+	// ErrWindowFull indicates there are too many outstanding requests, which are waiting for responses.
+	ErrWindowFull = fmt.Errorf("Window is full. Too many outstanding requests")
+
+	// ErrResponseTimeout indicates SMSC did not respond to request in time.
+	ErrResponseTimeout = fmt.Errorf("Timeout waiting for response from SMSC")
+
+	// ErrRequestReplaced indicates outstanding request is replaced by another one with the same sequence number,
+	// hence its response could not be matched anymore.
+	ErrRequestReplaced = fmt.Errorf("Request is replaced by another one with the same sequence number")
+)
+
+type response struct {
+	p   pdu.PDU
+	err error
+}
+
+type pendingRequest struct {
+	p        pdu.PDU
+	ch       chan response // nil if no one waits for response
+	timeout  time.Duration // zero if request never expires
+	deadline time.Time     // zero until request is written
+}
+
+// pendingRequests tracks outstanding requests, which are waiting for responses, by sequence number.
+//
+// If window is set, number of outstanding requests is limited by its capacity.
+type pendingRequests struct {
+	lock     sync.Mutex
+	requests map[int32]*pendingRequest
+	window   chan struct{}
+}
+
+// acquire a slot in window.
+//
+// Zero wait duration fails fast with ErrWindowFull. Negative one waits until
+// a slot is available or ctx/done is closed.
+func (r *pendingRequests) acquire(ctx context.Context, done <-chan struct{}, wait time.Duration) (err error) {
+	if r.window == nil {
+		return
+	}
+
+	select {
+	case r.window <- struct{}{}:
+		return
+
+	default:
+		if wait == 0 {
+			err = ErrWindowFull
+			return
+		}
+	}
+
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case r.window <- struct{}{}:
+
+	case <-ctx.Done():
+		err = ctx.Err()
+
+	case <-done:
+		err = ErrTransmitterClosing
+
+	case <-timeout:
+		err = ErrWindowFull
+	}
+
+	return
+}
+
+// release a slot in window.
+func (r *pendingRequests) release() {
+	if r.window != nil {
+		<-r.window
+	}
+}
+
+// inflight returns number of outstanding requests.
+func (r *pendingRequests) inflight() (n int) {
+	r.lock.Lock()
+	n = len(r.requests)
+	r.lock.Unlock()
+	return
+}
+
+// register an outstanding request, which occupied a slot in window.
+// Returned channel receives the response if wait is true.
+func (r *pendingRequests) register(p pdu.PDU, wait bool, timeout time.Duration) (ch chan response) {
+	req := &pendingRequest{p: p, timeout: timeout}
+	if wait {
+		ch = make(chan response, 1)
+		req.ch = ch
+	}
+
+	seq := p.GetSequenceNumber()
+
+	r.lock.Lock()
+	if r.requests == nil {
+		r.requests = make(map[int32]*pendingRequest)
+	}
+	replaced, duplicated := r.requests[seq]
+	r.requests[seq] = req
+	r.lock.Unlock()
+
+	// replaced request no longer occupies its slot, nor waits for response
+	if duplicated {
+		r.release()
+		if replaced.ch != nil {
+			replaced.ch <- response{err: ErrRequestReplaced}
+		}
+	}
+
+	return
+}
+
+// take removes outstanding request with sequence number.
+func (r *pendingRequests) take(seq int32) (req *pendingRequest) {
+	r.lock.Lock()
+	req, ok := r.requests[seq]
+	if ok {
+		delete(r.requests, seq)
+	}
+	r.lock.Unlock()
+
+	if ok {
+		r.release()
+	}
+
+	return
+}
+
+// remove outstanding request with sequence number.
+func (r *pendingRequests) remove(seq int32) {
+	_ = r.take(seq)
+}
+
+// written starts response timeout of outstanding request with sequence number,
+// once it is written to connection.
+func (r *pendingRequests) written(seq int32, now time.Time) {
+	r.lock.Lock()
+	if req, ok := r.requests[seq]; ok && req.timeout > 0 {
+		req.deadline = now.Add(req.timeout)
+	}
+	r.lock.Unlock()
+}
+
+// detach waiter from outstanding request with sequence number.
+//
+// Request is kept to occupy its slot in window until response
+// arrives or it expires.
+func (r *pendingRequests) detach(seq int32) {
+	r.lock.Lock()
+	if req, ok := r.requests[seq]; ok {
+		req.ch = nil
+	}
+	r.lock.Unlock()
+}
+
+// fail outstanding request with sequence number.
+func (r *pendingRequests) fail(seq int32, err error) {
+	if req := r.take(seq); req != nil && req.ch != nil {
+		req.ch <- response{err: err}
+	}
+}
+
+// failAll fails all outstanding requests with error.
+func (r *pendingRequests) failAll(err error) {
+	r.lock.Lock()
+	requests := r.requests
+	r.requests = nil
+	r.lock.Unlock()
+
+	for _, req := range requests {
+		r.release()
+		if req.ch != nil {
+			req.ch <- response{err: err}
+		}
+	}
+}
+
+// expire removes outstanding requests which passed their deadline.
+// Waiters of expired requests are failed with ErrResponseTimeout.
+func (r *pendingRequests) expire(now time.Time) (expired []pdu.PDU) {
+	r.lock.Lock()
+	var requests []*pendingRequest
+	for seq, req := range r.requests {
+		if !req.deadline.IsZero() && now.After(req.deadline) {
+			delete(r.requests, seq)
+			requests = append(requests, req)
+		}
+	}
+	r.lock.Unlock()
+
+	for _, req := range requests {
+		r.release()
+		if req.ch != nil {
+			req.ch <- response{err: ErrResponseTimeout}
+		}
+		expired = append(expired, req.p)
+	}
+
+	return
+}
+
+// resolve passes response PDU to its waiter.
+// Returns false if there is no one waiting for this PDU.
+func (r *pendingRequests) resolve(p pdu.PDU) (resolved bool) {
+	_, resolved = r.match(p)
+	return
+}
+
+// match takes outstanding request of response PDU and passes response to its waiter.
+// Resolved is false if there is no one waiting for this PDU.
+func (r *pendingRequests) match(p pdu.PDU) (request pdu.PDU, resolved bool) {
+	if !isResponse(p) {
+		return
+	}
+
+	if req := r.take(p.GetSequenceNumber()); req != nil {
+		request = req.p
+
+		if req.ch != nil {
+			if p.IsGNack() {
+				req.ch <- response{p: p, err: ErrGenericNack}
+			} else {
+				req.ch <- response{p: p}
+			}
+			resolved = true
+		}
+	}
+
+	return
+}
+
+// isResponse checks if PDU is a response, indicated by the most significant bit of command id.
+func isResponse(p pdu.PDU) bool {
+	return p != nil && p.GetHeader().CommandID < 0
+}
@@ -0,0 +1,205 @@
+package gosmpp
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMSC answers every request on conn with response built by respond.
+func fakeSMSC(conn net.Conn, respond func(pdu.PDU) pdu.PDU) {
+	for {
+		p, err := pdu.Parse(conn)
+		if err != nil {
+			return
+		}
+
+		if resp := respond(p); resp != nil {
+			if _, err = conn.Write(marshal(resp)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestPendingRequests(t *testing.T) {
+	t.Run("resolve", func(t *testing.T) {
+		var r pendingRequests
+
+		req := pdu.NewSubmitSM()
+		ch := r.register(req, true, 0)
+
+		// requests are never resolved
+		require.False(t, r.resolve(pdu.NewSubmitSM()))
+
+		resp := req.GetResponse()
+		require.True(t, r.resolve(resp))
+		require.False(t, r.resolve(resp))
+		require.Equal(t, resp, (<-ch).p)
+
+		nack := pdu.NewGenericNack()
+		ch = r.register(nack, true, 0)
+		require.True(t, r.resolve(nack))
+		require.Equal(t, ErrGenericNack, (<-ch).err)
+
+		// waiter of replaced request is failed
+		dup := pdu.NewSubmitSM()
+		dup.SetSequenceNumber(req.GetSequenceNumber())
+		ch = r.register(req, true, 0)
+		chDup := r.register(dup, true, 0)
+		require.Equal(t, ErrRequestReplaced, (<-ch).err)
+		require.Equal(t, 1, r.inflight())
+		require.True(t, r.resolve(dup.GetResponse()))
+		require.Equal(t, dup.GetResponse().GetSequenceNumber(), (<-chDup).p.GetSequenceNumber())
+
+		ch = r.register(pdu.NewCancelSM(), true, 0)
+		r.failAll(ErrTransmitterClosing)
+		require.Equal(t, ErrTransmitterClosing, (<-ch).err)
+		require.Zero(t, r.inflight())
+	})
+
+	t.Run("window", func(t *testing.T) {
+		r := pendingRequests{window: make(chan struct{}, 2)}
+		ctx := context.Background()
+
+		req1, req2 := pdu.NewSubmitSM(), pdu.NewSubmitSM()
+		for _, req := range []pdu.PDU{req1, req2} {
+			require.Nil(t, r.acquire(ctx, nil, 0))
+			_ = r.register(req, false, 20*time.Millisecond)
+		}
+		require.Equal(t, 2, r.inflight())
+
+		// response timeout starts once request is written
+		require.Empty(t, r.expire(time.Now().Add(time.Second)))
+		r.written(req2.GetSequenceNumber(), time.Now())
+
+		// fail fast
+		require.Equal(t, ErrWindowFull, r.acquire(ctx, nil, 0))
+
+		// wait with timeout
+		require.Equal(t, ErrWindowFull, r.acquire(ctx, nil, 10*time.Millisecond))
+
+		// response releases slot
+		require.False(t, r.resolve(req1.GetResponse()))
+		require.Equal(t, 1, r.inflight())
+		require.Nil(t, r.acquire(ctx, nil, 0))
+		r.release()
+
+		// expiration releases slot
+		expired := r.expire(time.Now().Add(time.Second))
+		require.Equal(t, []pdu.PDU{req2}, expired)
+		require.Zero(t, r.inflight())
+
+		// wait until released
+		for i := 0; i < 2; i++ {
+			require.Nil(t, r.acquire(ctx, nil, 0))
+		}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			r.release()
+		}()
+		require.Nil(t, r.acquire(ctx, nil, -1))
+	})
+}
+
+func TestSubmitAndWait(t *testing.T) {
+	client, server := net.Pipe()
+	go fakeSMSC(server, func(p pdu.PDU) pdu.PDU {
+		switch pd := p.(type) {
+		case *pdu.SubmitSM:
+			resp := pd.GetResponse().(*pdu.SubmitSMResp)
+			resp.MessageID = "msg-id"
+			return resp
+
+		case *pdu.QuerySM:
+			nack := pdu.NewGenericNack()
+			nack.SetSequenceNumber(pd.GetSequenceNumber())
+			return nack
+
+		default:
+			return nil // never respond
+		}
+	})
+
+	var unattributed int32
+	trans := NewTransmitter(NewConnection(client), TransmitSettings{
+		IdleTimeout: time.Second,
+		OnPDU: func(p pdu.PDU, _ bool) {
+			atomic.AddInt32(&unattributed, 1)
+		},
+	})
+	defer func() {
+		_ = trans.Close()
+	}()
+
+	// every request is tracked, hence never without deadline
+	require.Equal(t, defaultResponseTimeout, trans.(*transceiver).out.settings.ResponseTimeout)
+
+	t.Run("response", func(t *testing.T) {
+		resp, err := trans.SubmitAndWait(context.Background(), newSubmitSM("abc"))
+		require.Nil(t, err)
+		require.EqualValues(t, data.ESME_ROK, resp.GetHeader().CommandStatus)
+		require.Equal(t, "msg-id", resp.(*pdu.SubmitSMResp).MessageID)
+	})
+
+	t.Run("genericNack", func(t *testing.T) {
+		resp, err := trans.SubmitAndWait(context.Background(), pdu.NewQuerySM())
+		require.Equal(t, ErrGenericNack, err)
+		require.True(t, resp.IsGNack())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := trans.SubmitAndWait(ctx, pdu.NewCancelSM())
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("noResponse", func(t *testing.T) {
+		_, err := trans.SubmitAndWait(context.Background(), pdu.NewGenericNack())
+		require.Equal(t, ErrNoResponse, err)
+	})
+
+	require.Zero(t, atomic.LoadInt32(&unattributed))
+}
+
+func TestSubmitWithWindow(t *testing.T) {
+	client, server := net.Pipe()
+	go fakeSMSC(server, func(p pdu.PDU) pdu.PDU {
+		return nil // never respond
+	})
+
+	var expired int32
+	trans := NewTransceiver(NewConnection(client), TransceiveSettings{
+		IdleTimeout:     time.Second,
+		WindowSize:      2,
+		ResponseTimeout: 100 * time.Millisecond,
+		OnResponseTimeout: func(p pdu.PDU, err error) {
+			require.Equal(t, ErrResponseTimeout, err)
+			atomic.AddInt32(&expired, 1)
+		},
+	})
+	defer func() {
+		_ = trans.Close()
+	}()
+
+	require.Nil(t, trans.Submit(newSubmitSM("abc")))
+	require.Nil(t, trans.Submit(newSubmitSM("abc")))
+	require.Equal(t, ErrWindowFull, trans.Submit(newSubmitSM("abc")))
+
+	// responses are never sent, slots are released after expiration
+	time.Sleep(300 * time.Millisecond)
+	require.EqualValues(t, 2, atomic.LoadInt32(&expired))
+	require.Nil(t, trans.Submit(newSubmitSM("abc")))
+
+	_, err := trans.SubmitAndWait(context.Background(), newSubmitSM("abc"))
+	require.Equal(t, ErrResponseTimeout, err)
+}
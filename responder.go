@@ -0,0 +1,59 @@
+package gosmpp
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrAlreadyResponded indicates request is already responded.
+	ErrAlreadyResponded = fmt.Errorf("Request is already responded")
+)
+
+// Responder responds to a received request, either within RequestCallback or later,
+// e.g. after the request is persisted. Request must be responded once,
+// otherwise SMSC would consider it failed and retry.
+//
+// Responder is safe for concurrent use.
+type Responder struct {
+	request   pdu.PDU
+	send      func(pdu.PDU) error
+	lock      sync.Mutex
+	responded bool
+}
+
+// Request returns received request.
+func (r *Responder) Request() pdu.PDU {
+	return r.request
+}
+
+// Respond with given response, e.g. a populated DataSMResp built from request's GetResponse.
+// Sequence number of response is set to the request's one.
+//
+// Request is considered responded only once response is sent, hence responding
+// could be retried after error.
+func (r *Responder) Respond(resp pdu.PDU) (err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.responded {
+		return ErrAlreadyResponded
+	}
+
+	resp.SetSequenceNumber(r.request.GetSequenceNumber())
+	if err = r.send(resp); err == nil {
+		r.responded = true
+	}
+	return
+}
+
+// RespondStatus responds with default response carrying command status,
+// e.g. data.ESME_RX_T_APPN asking SMSC to retry delivering later.
+func (r *Responder) RespondStatus(status data.CommandStatusType) error {
+	resp := r.request.GetResponse()
+	resp.SetCommandStatus(status)
+	return r.Respond(resp)
+}
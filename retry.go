@@ -0,0 +1,186 @@
+package gosmpp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/errors"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrRejected indicates SMSC rejected request with error command status.
+	ErrRejected = fmt.Errorf("Request is rejected by SMSC")
+)
+
+// RetryPolicy re-submits requests which SMSC rejected transiently or did not respond to
+// within ResponseTimeout.
+//
+// Only requests submitted with Submit are retried. Ones submitted with SubmitAndWait are left to
+// their waiters. Retried PDU is re-submitted as is, with new sequence number only, hence segments
+// of split message keep their concatenation info and are still reassembled by SMSC.
+//
+// Responses of retried attempts are not passed to OnPDU. Final response is passed as usual.
+type RetryPolicy struct {
+	// MaxAttempts is maximum number of attempts, including the first one.
+	//
+	// Zero or one disables retrying.
+	MaxAttempts int
+
+	// Backoff decides delay before re-submitting, after given number of failed attempts.
+	// Returning false gives up retrying.
+	//
+	// Default: ExponentialBackoff starting at 1 sec.
+	Backoff BackoffPolicy
+
+	// Retryable classifies error command status of response as transient.
+	//
+	// Default: IsRetryable
+	Retryable func(data.CommandStatusType) bool
+
+	// OnFailed notifies request which is finally failed, either rejected permanently
+	// or running out of attempts. Error is *RetryError.
+	OnFailed PDUErrorCallback
+}
+
+func (s *RetryPolicy) normalize() {
+	if s.Backoff == nil {
+		s.Backoff = ExponentialBackoff{}
+	}
+
+	if s.Retryable == nil {
+		s.Retryable = IsRetryable
+	}
+}
+
+// IsRetryable checks if command status indicates transient error, which is worth retrying:
+// ESME_RTHROTTLED, ESME_RMSGQFUL, ESME_RSYSERR or ESME_RX_T_APPN.
+func IsRetryable(status data.CommandStatusType) bool {
+	return (&errors.StatusError{Status: status}).IsTemporary()
+}
+
+// RetryError describes request which is finally failed.
+type RetryError struct {
+	// Attempts is number of attempts made.
+	Attempts int
+
+	// Response is the last response from SMSC, nil if not responded.
+	Response pdu.PDU
+
+	// Err is cause of failure: ErrRejected, ErrResponseTimeout or error of re-submitting.
+	Err error
+}
+
+// Error interface.
+func (e *RetryError) Error() string {
+	if e.Response != nil {
+		return fmt.Sprintf("Request failed after %d attempt(s): %v. Command status: [%d]", e.Attempts, e.Err, e.Response.GetHeader().CommandStatus)
+	}
+	return fmt.Sprintf("Request failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns cause of failure.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retrier counts attempts of requests submitted with Submit, deciding their retrying.
+type retrier struct {
+	policy   RetryPolicy
+	lock     sync.Mutex
+	attempts map[pdu.PDU]int
+}
+
+func newRetrier(policy RetryPolicy) *retrier {
+	if policy.MaxAttempts <= 1 {
+		return nil
+	}
+
+	policy.normalize()
+	return &retrier{
+		policy:   policy,
+		attempts: make(map[pdu.PDU]int),
+	}
+}
+
+// sent counts an attempt of request.
+func (r *retrier) sent(p pdu.PDU) {
+	r.lock.Lock()
+	r.attempts[p]++
+	r.lock.Unlock()
+}
+
+// unsent uncounts an attempt of request, which is failed to submit.
+func (r *retrier) unsent(p pdu.PDU) {
+	r.lock.Lock()
+	if n, ok := r.attempts[p]; ok {
+		if n <= 1 {
+			delete(r.attempts, p)
+		} else {
+			r.attempts[p] = n - 1
+		}
+	}
+	r.lock.Unlock()
+}
+
+// decide on response of request, nil response means request is not responded in time.
+// Returns false if request is settled or not tracked at all.
+func (r *retrier) decide(p, resp pdu.PDU) (delay time.Duration, retry bool) {
+	r.lock.Lock()
+	attempts, ok := r.attempts[p]
+	r.lock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	var cause error
+	if resp == nil {
+		cause = ErrResponseTimeout
+	} else if status := resp.GetHeader().CommandStatus; status != data.ESME_ROK {
+		cause = ErrRejected
+		retry = r.policy.Retryable(status)
+	}
+
+	if cause == nil {
+		r.forget(p)
+		return
+	}
+
+	if resp == nil || retry {
+		if attempts < r.policy.MaxAttempts {
+			if delay, retry = r.policy.Backoff.Next(attempts); retry {
+				return
+			}
+		}
+		retry = false
+	}
+
+	r.fail(p, &RetryError{Attempts: attempts, Response: resp, Err: cause})
+	return
+}
+
+// fail notifies request which is finally failed.
+func (r *retrier) fail(p pdu.PDU, err *RetryError) {
+	r.forget(p)
+	if r.policy.OnFailed != nil {
+		r.policy.OnFailed(p, err)
+	}
+}
+
+// abandon request which could not be re-submitted.
+func (r *retrier) abandon(p pdu.PDU, err error) {
+	r.lock.Lock()
+	attempts := r.attempts[p]
+	r.lock.Unlock()
+
+	r.fail(p, &RetryError{Attempts: attempts, Err: err})
+}
+
+func (r *retrier) forget(p pdu.PDU) {
+	r.lock.Lock()
+	delete(r.attempts, p)
+	r.lock.Unlock()
+}
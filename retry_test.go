@@ -0,0 +1,326 @@
+package gosmpp
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func responseOf(p pdu.PDU, status data.CommandStatusType) pdu.PDU {
+	resp := p.GetResponse()
+	resp.SetCommandStatus(status)
+	return resp
+}
+
+func TestRetrier(t *testing.T) {
+	require.Nil(t, newRetrier(RetryPolicy{MaxAttempts: 1}))
+	require.True(t, IsRetryable(data.ESME_RTHROTTLED))
+	require.False(t, IsRetryable(data.ESME_RINVDSTADR))
+
+	var failed []error
+	r := newRetrier(RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     ConstantBackoff{Interval: time.Second},
+		OnFailed: func(p pdu.PDU, err error) {
+			failed = append(failed, err)
+		},
+	})
+
+	// untracked
+	p := newSubmitSM("retrier")
+	_, retry := r.decide(p, responseOf(p, data.ESME_RTHROTTLED))
+	require.False(t, retry)
+
+	// transient, then exhausted
+	r.sent(p)
+	delay, retry := r.decide(p, responseOf(p, data.ESME_RTHROTTLED))
+	require.True(t, retry)
+	require.Equal(t, time.Second, delay)
+
+	r.sent(p)
+	_, retry = r.decide(p, nil)
+	require.False(t, retry)
+	require.Len(t, failed, 1)
+
+	var retryErr *RetryError
+	require.True(t, errors.As(failed[0], &retryErr))
+	require.Equal(t, 2, retryErr.Attempts)
+	require.Nil(t, retryErr.Response)
+	require.True(t, errors.Is(failed[0], ErrResponseTimeout))
+
+	// not responded in time, then retried
+	r.sent(p)
+	delay, retry = r.decide(p, nil)
+	require.True(t, retry)
+	require.Equal(t, time.Second, delay)
+	require.Len(t, failed, 1)
+
+	// failed to re-submit
+	r.sent(p)
+	r.unsent(p)
+	r.abandon(p, ErrTransmitterClosing)
+	require.Len(t, failed, 2)
+	require.True(t, errors.As(failed[1], &retryErr))
+	require.Equal(t, 1, retryErr.Attempts)
+	require.True(t, errors.Is(failed[1], ErrTransmitterClosing))
+
+	// permanent
+	r.sent(p)
+	_, retry = r.decide(p, responseOf(p, data.ESME_RINVDSTADR))
+	require.False(t, retry)
+	require.Len(t, failed, 3)
+	require.True(t, errors.As(failed[2], &retryErr))
+	require.Equal(t, 1, retryErr.Attempts)
+	require.True(t, errors.Is(failed[2], ErrRejected))
+
+	// succeeded
+	r.sent(p)
+	_, retry = r.decide(p, responseOf(p, data.ESME_ROK))
+	require.False(t, retry)
+	require.Len(t, failed, 3)
+	require.Empty(t, r.attempts)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	accounts := make(map[string]string)
+	for _, pair := range auths {
+		accounts[pair[0]] = pair[1]
+	}
+
+	smsc, err := smpptest.NewSMSC(smpptest.Settings{Accounts: accounts})
+	require.Nil(t, err)
+	defer func() {
+		_ = smsc.Close()
+	}()
+
+	auth := nextAuth()
+	auth.SMSC = smsc.Addr()
+
+	type failure struct {
+		p   pdu.PDU
+		err error
+	}
+
+	var (
+		lock      sync.Mutex
+		responses []pdu.PDU
+		failures  []failure
+	)
+
+	connect := func(t *testing.T, timeout time.Duration) Transceiver {
+		lock.Lock()
+		responses, failures = nil, nil
+		lock.Unlock()
+		smsc.Reset()
+
+		conn, err := ConnectAsTransceiver(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		return NewTransceiver(conn, TransceiveSettings{
+			ResponseTimeout: timeout,
+			RetryPolicy: RetryPolicy{
+				MaxAttempts: 3,
+				Backoff:     ConstantBackoff{Interval: 10 * time.Millisecond},
+				OnFailed: func(p pdu.PDU, err error) {
+					lock.Lock()
+					failures = append(failures, failure{p: p, err: err})
+					lock.Unlock()
+				},
+			},
+			OnPDU: func(p pdu.PDU, _ bool) {
+				if _, ok := p.(*pdu.SubmitSMResp); ok {
+					lock.Lock()
+					responses = append(responses, p)
+					lock.Unlock()
+				}
+			},
+		})
+	}
+
+	settled := func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(responses) > 0 || len(failures) > 0
+	}
+
+	t.Run("transient", func(t *testing.T) {
+		trans := connect(t, 0)
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		smsc.ReplyNext(smpptest.Reply{Status: data.ESME_RTHROTTLED}, smpptest.Reply{Status: data.ESME_RMSGQFUL})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		_, err := smsc.Wait(data.SUBMIT_SM, 3, time.Second)
+		require.Nil(t, err)
+		require.Eventually(t, settled, time.Second, 10*time.Millisecond)
+
+		// only the final response is passed
+		lock.Lock()
+		require.Len(t, responses, 1)
+		require.EqualValues(t, data.ESME_ROK, responses[0].GetHeader().CommandStatus)
+		require.Empty(t, failures)
+		lock.Unlock()
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		trans := connect(t, 0)
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		smsc.ReplyNext(
+			smpptest.Reply{Status: data.ESME_RSYSERR},
+			smpptest.Reply{Status: data.ESME_RTHROTTLED},
+			smpptest.Reply{Status: data.ESME_RTHROTTLED},
+		)
+		submitSM := newSubmitSM(auth.SystemID)
+		require.Nil(t, trans.Submit(submitSM))
+
+		require.Eventually(t, settled, time.Second, 10*time.Millisecond)
+		require.Len(t, smsc.ReceivedOf(data.SUBMIT_SM), 3)
+
+		lock.Lock()
+		defer lock.Unlock()
+		require.Len(t, failures, 1)
+		require.Equal(t, submitSM, failures[0].p)
+
+		var retryErr *RetryError
+		require.True(t, errors.As(failures[0].err, &retryErr))
+		require.Equal(t, 3, retryErr.Attempts)
+		require.EqualValues(t, data.ESME_RTHROTTLED, retryErr.Response.GetHeader().CommandStatus)
+		require.True(t, errors.Is(retryErr, ErrRejected))
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		trans := connect(t, 0)
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		smsc.ReplyNext(smpptest.Reply{Status: data.ESME_RINVDSTADR})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		require.Eventually(t, settled, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, smsc.ReceivedOf(data.SUBMIT_SM), 1)
+
+		lock.Lock()
+		defer lock.Unlock()
+		require.Len(t, failures, 1)
+		require.Len(t, responses, 1)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		trans := connect(t, 100*time.Millisecond)
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		smsc.ReplyNext(smpptest.Reply{Drop: true})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		_, err := smsc.Wait(data.SUBMIT_SM, 2, 2*time.Second)
+		require.Nil(t, err)
+		require.Eventually(t, settled, time.Second, 10*time.Millisecond)
+
+		lock.Lock()
+		defer lock.Unlock()
+		require.Len(t, responses, 1)
+		require.Empty(t, failures)
+	})
+
+	t.Run("window", func(t *testing.T) {
+		lock.Lock()
+		responses, failures = nil, nil
+		lock.Unlock()
+		smsc.Reset()
+
+		conn, err := ConnectAsTransceiver(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		trans := NewTransceiver(conn, TransceiveSettings{
+			WindowSize: 1,
+			RetryPolicy: RetryPolicy{
+				MaxAttempts: 3,
+				Backoff:     ConstantBackoff{Interval: 100 * time.Millisecond},
+				OnFailed: func(p pdu.PDU, err error) {
+					lock.Lock()
+					failures = append(failures, failure{p: p, err: err})
+					lock.Unlock()
+				},
+			},
+			OnPDU: func(p pdu.PDU, _ bool) {
+				if _, ok := p.(*pdu.SubmitSMResp); ok {
+					lock.Lock()
+					responses = append(responses, p)
+					lock.Unlock()
+				}
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		// slot of throttled request is taken by another one while backing off
+		smsc.ReplyNext(smpptest.Reply{Status: data.ESME_RTHROTTLED}, smpptest.Reply{Delay: 200 * time.Millisecond})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+		require.Eventually(t, func() bool {
+			return trans.Submit(newSubmitSM(auth.SystemID)) == nil
+		}, time.Second, 10*time.Millisecond)
+
+		// retry waits for free slot instead of failing
+		_, err = smsc.Wait(data.SUBMIT_SM, 3, 2*time.Second)
+		require.Nil(t, err)
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(responses) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		lock.Lock()
+		defer lock.Unlock()
+		require.Empty(t, failures)
+	})
+
+	t.Run("segments", func(t *testing.T) {
+		trans := connect(t, 0)
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		long := newSubmitSM(auth.SystemID)
+		require.Nil(t, long.Message.SetLongMessageWithEnc(strings.Repeat("segment ", 40), data.GSM7BIT))
+		segments, err := long.Split()
+		require.Nil(t, err)
+		require.True(t, len(segments) > 1)
+
+		// the first segment is throttled
+		smsc.ReplyNext(smpptest.Reply{Status: data.ESME_RTHROTTLED})
+		for _, segment := range segments {
+			require.Nil(t, trans.Submit(segment))
+		}
+
+		received, err := smsc.Wait(data.SUBMIT_SM, len(segments)+1, time.Second)
+		require.Nil(t, err)
+
+		// retried segment keeps its concatenation info
+		first, retried := received[0].(*pdu.SubmitSM), received[len(segments)].(*pdu.SubmitSM)
+		total, part, ref, found := first.Message.UDH().GetConcatInfo()
+		require.True(t, found)
+
+		rTotal, rPart, rRef, found := retried.Message.UDH().GetConcatInfo()
+		require.True(t, found)
+		require.Equal(t, []byte{total, part, ref}, []byte{rTotal, rPart, rRef})
+		require.NotEqual(t, first.SequenceNumber, retried.SequenceNumber)
+	})
+}
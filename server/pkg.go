@@ -0,0 +1,44 @@
+// Package server implements SMSC side of SMPP (3.4): accepting connections,
+// authenticating bind requests and serving bound sessions.
+package server
+
+import (
+	"fmt"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrServerClosed indicates server is closed.
+	ErrServerClosed = fmt.Errorf("Server is closed")
+
+	// ErrSessionClosed indicates session is closed. Can not send any PDU.
+	ErrSessionClosed = fmt.Errorf("Session is closed. Can not send PDU to ESME")
+
+	// ErrNotReceiver indicates session is bound as transmitter, thus could not receive deliver_sm.
+	ErrNotReceiver = fmt.Errorf("Session is not bound as receiver or transceiver")
+
+	// ErrNoSession indicates there is no bound session to deliver PDU to.
+	ErrNoSession = fmt.Errorf("No bound session to deliver PDU")
+)
+
+// Authenticator authenticates bind request from ESME.
+//
+// Returning ESME_ROK accepts the bind. Other statuses (ESME_RINVPASWD, ESME_RINVSYSID, ...)
+// are responded to ESME and connection is closed.
+type Authenticator func(systemID, password, systemType string, addressRange pdu.AddressRange) data.CommandStatusType
+
+// Handler handles PDU received from bound session.
+//
+// Returned PDU is sent back to ESME as response. Returning nil means no response.
+type Handler func(s *Session, p pdu.PDU) pdu.PDU
+
+// Observer observes PDU received from session.
+type Observer func(s *Session, p pdu.PDU)
+
+// SessionCallback notifies session event.
+type SessionCallback func(s *Session)
+
+// ErrorCallback notifies happened error.
+type ErrorCallback func(error)
@@ -0,0 +1,339 @@
+package server
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+const (
+	defaultBindTimeout = 5 * time.Second
+)
+
+// Settings is settings for Server.
+type Settings struct {
+	// SystemID identifies SMSC, returned to ESME within bind_resp.
+	SystemID string
+
+	// Authenticator authenticates bind requests.
+	//
+	// Nil Authenticator accepts all binds.
+	Authenticator Authenticator
+
+	// BindTimeout is maximum duration to wait for bind request after accepting connection.
+	//
+	// Default: 5 secs
+	BindTimeout time.Duration
+
+	// WriteTimeout is timeout for writing PDU to ESME.
+	//
+	// Zero duration means no timeout.
+	WriteTimeout time.Duration
+
+	// OnPDU handles PDU(s) received from bound sessions.
+	// EnquireLink and Unbind are handled automatically.
+	//
+	// Default: responds with DefaultResponse.
+	OnPDU Handler
+
+	// OnReceive observes every PDU received from ESME, including bind request,
+	// enquire_link and unbind, before it is handled.
+	OnReceive Observer
+
+	// OnBound notifies new bound session.
+	OnBound SessionCallback
+
+	// OnClosed notifies closed session.
+	OnClosed SessionCallback
+
+	// OnError notifies happened error while accepting connections or serving sessions.
+	OnError ErrorCallback
+}
+
+func (s *Settings) normalize() {
+	if s.BindTimeout <= 0 {
+		s.BindTimeout = defaultBindTimeout
+	}
+}
+
+// Server is SMPP server (SMSC side).
+type Server struct {
+	settings  Settings
+	lock      sync.RWMutex
+	listeners map[net.Listener]struct{}
+	sessions  map[*Session]struct{}
+	wg        sync.WaitGroup
+	state     int32
+	messageID uint64
+}
+
+// NewServer returns new Server.
+func NewServer(settings Settings) *Server {
+	settings.normalize()
+
+	return &Server{
+		settings:  settings,
+		listeners: make(map[net.Listener]struct{}),
+		sessions:  make(map[*Session]struct{}),
+	}
+}
+
+// ListenAndServe listens on TCP network address and serves incoming connections.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts incoming connections on listener, creating new session for each.
+// Serve always returns non-nil error. After Close, returned error is ErrServerClosed.
+func (s *Server) Serve(l net.Listener) (err error) {
+	if !s.track(l) {
+		_ = l.Close()
+		return ErrServerClosed
+	}
+	defer s.untrack(l)
+
+	for {
+		var conn net.Conn
+		if conn, err = l.Accept(); err != nil {
+			if atomic.LoadInt32(&s.state) != 0 {
+				err = ErrServerClosed
+				return
+			}
+
+			if nErr, ok := err.(net.Error); ok && nErr.Temporary() {
+				s.notifyError(err)
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+
+			return
+		}
+
+		if !s.spawn(conn) {
+			err = ErrServerClosed
+			return
+		}
+	}
+}
+
+// spawn serves connection in background, unless server is closed. Closed state is checked
+// under the same lock as Close, so that Close waits for every spawned connection.
+func (s *Server) spawn(conn net.Conn) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if atomic.LoadInt32(&s.state) != 0 {
+		_ = conn.Close()
+		return false
+	}
+
+	s.wg.Add(1)
+	go func() {
+		s.serve(conn)
+		s.wg.Done()
+	}()
+	return true
+}
+
+func (s *Server) track(l net.Listener) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if atomic.LoadInt32(&s.state) != 0 {
+		return false
+	}
+
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(l net.Listener) {
+	s.lock.Lock()
+	delete(s.listeners, l)
+	s.lock.Unlock()
+}
+
+// Outbind connects to ESME listening on addr and requests it to bind with outbind.
+// Connection is served like accepted ones once ESME binds.
+func (s *Server) Outbind(addr, systemID, password string) (err error) {
+	if atomic.LoadInt32(&s.state) != 0 {
+		return ErrServerClosed
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, s.settings.BindTimeout)
+	if err != nil {
+		return
+	}
+
+	p := pdu.NewOutbind().(*pdu.Outbind)
+	p.SystemID, p.Password = systemID, password
+
+	if s.settings.WriteTimeout > 0 {
+		err = conn.SetWriteDeadline(time.Now().Add(s.settings.WriteTimeout))
+	}
+
+	if err == nil {
+		_, err = conn.Write(marshal(p))
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	if !s.spawn(conn) {
+		err = ErrServerClosed
+	}
+	return
+}
+
+// serve an accepted connection.
+func (s *Server) serve(conn net.Conn) {
+	sess := newSession(s, conn)
+
+	if err := sess.bind(); err != nil {
+		s.notifyError(err)
+		_ = conn.Close()
+		return
+	}
+
+	// register bound session
+	s.lock.Lock()
+	if atomic.LoadInt32(&s.state) != 0 {
+		s.lock.Unlock()
+		_ = sess.Close()
+		return
+	}
+	s.sessions[sess] = struct{}{}
+	s.lock.Unlock()
+
+	if s.settings.OnBound != nil {
+		s.settings.OnBound(sess)
+	}
+
+	sess.loop()
+
+	// unregister closed session
+	s.lock.Lock()
+	delete(s.sessions, sess)
+	s.lock.Unlock()
+
+	if s.settings.OnClosed != nil {
+		s.settings.OnClosed(sess)
+	}
+}
+
+// Sessions returns bound sessions.
+func (s *Server) Sessions() (sessions []*Session) {
+	s.lock.RLock()
+	sessions = make([]*Session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.lock.RUnlock()
+	return
+}
+
+// Deliver sends PDU (deliver_sm, data_sm, ...) to a session, bound with systemID as receiver or transceiver.
+func (s *Server) Deliver(systemID string, p pdu.PDU) (err error) {
+	err = ErrNoSession
+	for _, sess := range s.Sessions() {
+		if sess.SystemID() == systemID && sess.CanReceive() {
+			if err = sess.Send(p); err == nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// DefaultResponse returns default response for PDU. Responses for submit_sm,
+// submit_multi and data_sm carry newly generated message id.
+func (s *Server) DefaultResponse(p pdu.PDU) (resp pdu.PDU) {
+	if resp = p.GetResponse(); resp != nil {
+		switch r := resp.(type) {
+		case *pdu.SubmitSMResp:
+			r.MessageID = s.NextMessageID()
+
+		case *pdu.SubmitMultiResp:
+			r.MessageID = s.NextMessageID()
+
+		case *pdu.DataSMResp:
+			r.MessageID = s.NextMessageID()
+		}
+	}
+	return
+}
+
+// NextMessageID generates new message id.
+func (s *Server) NextMessageID() string {
+	return strconv.FormatUint(atomic.AddUint64(&s.messageID, 1), 16)
+}
+
+// Close server: stops listening and closes all sessions.
+func (s *Server) Close() (err error) {
+	s.lock.Lock()
+	if !atomic.CompareAndSwapInt32(&s.state, 0, 1) {
+		s.lock.Unlock()
+		return ErrServerClosed
+	}
+
+	for l := range s.listeners {
+		if e := l.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	sessions := make([]*Session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.lock.Unlock()
+
+	for _, sess := range sessions {
+		_ = sess.Close()
+	}
+
+	s.wg.Wait()
+	return
+}
+
+func (s *Server) authenticate(req *pdu.BindRequest) data.CommandStatusType {
+	if s.settings.Authenticator == nil {
+		return data.ESME_ROK
+	}
+	return s.settings.Authenticator(req.SystemID, req.Password, req.SystemType, req.AddressRange)
+}
+
+func (s *Server) handle(sess *Session, p pdu.PDU) pdu.PDU {
+	if s.settings.OnPDU != nil {
+		return s.settings.OnPDU(sess, p)
+	}
+
+	if p.CanResponse() {
+		return s.DefaultResponse(p)
+	}
+
+	return nil
+}
+
+func (s *Server) observe(sess *Session, p pdu.PDU) {
+	if s.settings.OnReceive != nil {
+		s.settings.OnReceive(sess, p)
+	}
+}
+
+func (s *Server) notifyError(err error) {
+	if s.settings.OnError != nil {
+		s.settings.OnError(err)
+	}
+}
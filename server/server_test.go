@@ -0,0 +1,228 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp"
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+
+	"github.com/stretchr/testify/require"
+)
+
+func startServer(t *testing.T, settings Settings) (s *Server, addr string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	s = NewServer(settings)
+	go func() {
+		_ = s.Serve(l)
+	}()
+
+	return s, l.Addr().String()
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition is not satisfied")
+}
+
+func TestServer(t *testing.T) {
+	errs := make(chan error, 10)
+	s, addr := startServer(t, Settings{
+		SystemID: "gosmpp-smsc",
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+		Authenticator: func(systemID, password, systemType string, addressRange pdu.AddressRange) data.CommandStatusType {
+			if password != "secret" {
+				return data.ESME_RINVPASWD
+			}
+			return data.ESME_ROK
+		},
+	})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	t.Run("authFailure", func(t *testing.T) {
+		_, err := gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, gosmpp.Auth{
+			SMSC:     addr,
+			SystemID: "esme",
+			Password: "wrong",
+		})
+		require.NotNil(t, err)
+	})
+
+	t.Run("transceiver", func(t *testing.T) {
+		conn, err := gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, gosmpp.Auth{
+			SMSC:     addr,
+			SystemID: "esme",
+			Password: "secret",
+		})
+		require.Nil(t, err)
+
+		delivered, unmatched := make(chan pdu.PDU, 1), make(chan pdu.PDU, 1)
+		trans := gosmpp.NewTransceiver(conn, gosmpp.TransceiveSettings{
+			ReadTimeout: time.Second,
+			OnPDU: func(p pdu.PDU, responded bool) {
+				switch p.(type) {
+				case *pdu.DeliverSM:
+					require.True(t, responded)
+					delivered <- p
+
+				case *pdu.SubmitSMResp:
+					unmatched <- p
+				}
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+		require.Equal(t, "gosmpp-smsc", trans.SystemID())
+
+		// submit
+		resp, err := trans.SubmitAndWait(context.Background(), pdu.NewSubmitSM())
+		require.Nil(t, err)
+		require.True(t, resp.IsOk())
+		require.NotEmpty(t, resp.(*pdu.SubmitSMResp).MessageID)
+
+		// enquire link
+		resp, err = trans.SubmitAndWait(context.Background(), pdu.NewEnquireLink())
+		require.Nil(t, err)
+		require.EqualValues(t, data.ENQUIRE_LINK_RESP, resp.GetHeader().CommandID)
+
+		// request with invalid TLV is handled as usual, session is kept
+		invalid := pdu.NewSubmitSM()
+		invalid.RegisterOptionalParam(pdu.Field{Tag: pdu.TagSmsSignal, Data: []byte{1, 2, 3}})
+		_, err = conn.Write(marshal(invalid))
+		require.Nil(t, err)
+
+		select {
+		case resp := <-unmatched:
+			require.True(t, resp.IsOk())
+		case <-time.After(time.Second):
+			t.Fatal("request with invalid TLV is not responded")
+		}
+
+		waitFor(t, func() bool {
+			select {
+			case err := <-errs:
+				_, ok := err.(*pdu.InvalidTLVError)
+				return ok
+			default:
+				return false
+			}
+		})
+
+		// unknown command id is nacked, session is kept
+		resp, err = trans.SubmitAndWait(context.Background(), pdu.NewUnknownPDU(0x00010200, []byte{0x01}))
+		require.Equal(t, gosmpp.ErrGenericNack, err)
+		require.Equal(t, data.ESME_RINVCMDID, resp.GetHeader().CommandStatus)
+
+		// deliver
+		waitFor(t, func() bool { return len(s.Sessions()) == 1 })
+		sess := s.Sessions()[0]
+		require.Equal(t, "esme", sess.SystemID())
+		require.Equal(t, pdu.Transceiver, sess.BindingType())
+		require.Nil(t, s.Deliver("esme", pdu.NewDeliverSM()))
+
+		select {
+		case <-delivered:
+		case <-time.After(time.Second):
+			t.Fatal("deliver_sm is not received")
+		}
+
+		require.Equal(t, ErrNoSession, s.Deliver("other", pdu.NewDeliverSM()))
+	})
+
+	t.Run("transmitter", func(t *testing.T) {
+		conn, err := gosmpp.ConnectAsTransmitter(gosmpp.NonTLSDialer, gosmpp.Auth{
+			SMSC:     addr,
+			SystemID: "transmitter",
+			Password: "secret",
+		})
+		require.Nil(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		waitFor(t, func() bool {
+			for _, sess := range s.Sessions() {
+				if sess.SystemID() == "transmitter" {
+					require.False(t, sess.CanReceive())
+					require.Equal(t, ErrNotReceiver, sess.Deliver(pdu.NewDeliverSM()))
+					return true
+				}
+			}
+			return false
+		})
+	})
+
+	t.Run("unbind", func(t *testing.T) {
+		conn, err := gosmpp.ConnectAsReceiver(gosmpp.NonTLSDialer, gosmpp.Auth{
+			SMSC:     addr,
+			SystemID: "receiver",
+			Password: "secret",
+		})
+		require.Nil(t, err)
+
+		closed := make(chan gosmpp.State, 1)
+		trans := gosmpp.NewTransceiver(conn, gosmpp.TransceiveSettings{
+			ReadTimeout: time.Second,
+			OnClosed: func(state gosmpp.State) {
+				closed <- state
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		waitFor(t, func() bool {
+			for _, sess := range s.Sessions() {
+				if sess.SystemID() == "receiver" {
+					require.Nil(t, sess.Unbind())
+					return true
+				}
+			}
+			return false
+		})
+
+		select {
+		case state := <-closed:
+			require.Equal(t, gosmpp.UnbindClosing, state)
+		case <-time.After(time.Second):
+			t.Fatal("unbind is not handled")
+		}
+	})
+}
+
+func TestServerClose(t *testing.T) {
+	s, addr := startServer(t, Settings{})
+
+	conn, err := gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, gosmpp.Auth{SMSC: addr})
+	require.Nil(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	waitFor(t, func() bool { return len(s.Sessions()) == 1 })
+	require.Nil(t, s.Close())
+	require.Empty(t, s.Sessions())
+	require.Equal(t, ErrServerClosed, s.Close())
+
+	_, err = gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, gosmpp.Auth{SMSC: addr})
+	require.NotNil(t, err)
+	require.Equal(t, ErrServerClosed, s.Outbind(addr, "", ""))
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/errors"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+// Session represents a bound ESME connection.
+type Session struct {
+	server      *Server
+	conn        net.Conn
+	systemID    string
+	systemType  string
+	bindingType pdu.BindingType
+	seq         pdu.SequenceGenerator
+	lock        sync.Mutex
+	state       int32
+}
+
+func newSession(server *Server, conn net.Conn) *Session {
+	return &Session{
+		server: server,
+		conn:   conn,
+		seq:    pdu.NewSequenceGenerator(),
+	}
+}
+
+// Server returns the server which session belongs to.
+func (s *Session) Server() *Server {
+	return s.server
+}
+
+// SystemID returns system id of bound ESME.
+func (s *Session) SystemID() string {
+	return s.systemID
+}
+
+// SystemType returns system type of bound ESME.
+func (s *Session) SystemType() string {
+	return s.systemType
+}
+
+// BindingType returns binding type of session.
+func (s *Session) BindingType() pdu.BindingType {
+	return s.bindingType
+}
+
+// CanReceive checks if session is bound as receiver or transceiver,
+// thus could receive deliver_sm from SMSC.
+func (s *Session) CanReceive() bool {
+	return s.bindingType == pdu.Receiver || s.bindingType == pdu.Transceiver
+}
+
+// RemoteAddr returns network address of ESME.
+func (s *Session) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// Send a PDU to ESME.
+func (s *Session) Send(p pdu.PDU) (err error) {
+	if err = p.ValidateOptionalParams(); err != nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if atomic.LoadInt32(&s.state) != 0 {
+		return ErrSessionClosed
+	}
+
+	if s.server.settings.WriteTimeout > 0 {
+		if err = s.conn.SetWriteDeadline(time.Now().Add(s.server.settings.WriteTimeout)); err != nil {
+			return
+		}
+	}
+
+	// requests are numbered per session, responses keep sequence numbers of their requests
+	if p.GetHeader().CommandID >= 0 {
+		p.SetSequenceNumber(s.seq.Next())
+	}
+
+	_, err = s.conn.Write(marshal(p))
+	return
+}
+
+// Deliver a PDU (deliver_sm, data_sm, ...) to ESME. Session must be bound as receiver or transceiver.
+func (s *Session) Deliver(p pdu.PDU) error {
+	if !s.CanReceive() {
+		return ErrNotReceiver
+	}
+	return s.Send(p)
+}
+
+// Unbind requests ESME to unbind. Session is closed after ESME responded.
+func (s *Session) Unbind() error {
+	return s.Send(pdu.NewUnbind())
+}
+
+// Close session and underlying connection.
+func (s *Session) Close() (err error) {
+	if atomic.CompareAndSwapInt32(&s.state, 0, 1) {
+		err = s.conn.Close()
+	}
+	return
+}
+
+// bind waits for bind request and authenticates it.
+func (s *Session) bind() (err error) {
+	if err = s.conn.SetReadDeadline(time.Now().Add(s.server.settings.BindTimeout)); err != nil {
+		return
+	}
+
+	p, err := pdu.Parse(s.conn)
+	if _, ok := err.(*pdu.InvalidTLVError); ok && p != nil {
+		s.server.notifyError(err)
+		err = nil
+	}
+	if err != nil {
+		return
+	}
+	s.server.observe(s, p)
+
+	req, ok := p.(*pdu.BindRequest)
+	if !ok {
+		if p.CanResponse() {
+			resp := p.GetResponse()
+			resp.SetCommandStatus(data.ESME_RINVBNDSTS)
+			_ = s.Send(resp)
+		}
+		return fmt.Errorf("Expected bind request but got: %T", p)
+	}
+
+	status := s.server.authenticate(req)
+
+	resp := req.GetResponse().(*pdu.BindResp)
+	resp.SystemID = s.server.settings.SystemID
+	resp.CommandStatus = status
+	if err = s.Send(resp); err != nil {
+		return
+	}
+
+	if status != data.ESME_ROK {
+		return fmt.Errorf("Bind rejected. SystemID: [%s]. Command status: [%d]", req.SystemID, status)
+	}
+
+	s.systemID = req.SystemID
+	s.systemType = req.SystemType
+	s.bindingType = req.BindingType
+
+	// no more read deadline
+	return s.conn.SetReadDeadline(time.Time{})
+}
+
+// loop reads and handles PDU(s) until session is closed.
+func (s *Session) loop() {
+	defer func() {
+		_ = s.Close()
+	}()
+
+	for {
+		p, err := pdu.Parse(s.conn)
+
+		// PDU with invalid TLV is handled as usual, after error is notified
+		if _, ok := err.(*pdu.InvalidTLVError); ok && p != nil {
+			s.server.notifyError(err)
+			err = nil
+		}
+
+		// PDU with unknown command id is nacked, session is kept
+		if err == errors.ErrUnknownCommandID && p != nil {
+			s.server.notifyError(err)
+			s.server.observe(s, p)
+
+			if p.CanResponse() {
+				if err = s.Send(p.GetResponse()); err != nil {
+					s.server.notifyError(err)
+					return
+				}
+			}
+			continue
+		}
+
+		if err != nil {
+			if err != io.EOF && atomic.LoadInt32(&s.state) == 0 {
+				s.server.notifyError(err)
+			}
+			return
+		}
+		s.server.observe(s, p)
+
+		switch pd := p.(type) {
+		case *pdu.EnquireLink:
+			_ = s.Send(pd.GetResponse())
+
+		case *pdu.Unbind:
+			_ = s.Send(pd.GetResponse())
+			return
+
+		case *pdu.UnbindResp:
+			return
+
+		case *pdu.BindRequest:
+			resp := pd.GetResponse()
+			resp.SetCommandStatus(data.ESME_RALYBND)
+			_ = s.Send(resp)
+
+		default:
+			if resp := s.server.handle(s, p); resp != nil {
+				if err = s.Send(resp); err != nil {
+					s.server.notifyError(err)
+					return
+				}
+			}
+		}
+	}
+}
+
+func marshal(p pdu.PDU) []byte {
+	buf := pdu.NewBuffer(make([]byte, 0, 64))
+	p.Marshal(buf)
+	return buf.Bytes()
+}
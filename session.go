@@ -0,0 +1,250 @@
+package gosmpp
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+// session is common core of TransmitterSession, ReceiverSession and TransceiverSession.
+// It binds underlying Transmitter/Receiver/Transceiver, rebinds it and fails back to preferred SMSC.
+type session struct {
+	dialer      Dialer
+	auth        Auth
+	bindingType pdu.BindingType
+	create      func(*Connection) io.Closer
+	rebinder    *rebinder
+	outbound    *outbound // nil unless OutboundStore is set
+	onClosed    ClosedCallback
+
+	r        atomic.Value // bound Transmitter, Receiver or Transceiver
+	endpoint atomic.Value // string
+
+	state     int32
+	rebinding int32
+}
+
+// sessionSettings are settings shared by all kinds of session.
+type sessionSettings struct {
+	RebindingBackoff  BackoffPolicy
+	OnRebindingError  ErrorCallback
+	OnRebindingGaveUp GiveUpCallback
+	OnClosed          ClosedCallback
+	OutboundStore     OutboundStore
+}
+
+func newSession(dialer Dialer, auth Auth, bindingType pdu.BindingType, settings sessionSettings, rebindingInterval time.Duration) *session {
+	policy := settings.RebindingBackoff
+	if policy == nil && rebindingInterval > 0 {
+		policy = ConstantBackoff{Interval: rebindingInterval}
+	}
+
+	s := &session{
+		dialer:      dialer,
+		auth:        auth,
+		bindingType: bindingType,
+		rebinder:    newRebinder(policy, settings.OnRebindingError, settings.OnRebindingGaveUp),
+		onClosed:    settings.OnClosed,
+	}
+
+	// keyed PDU(s) are acknowledged in store once finally responded
+	if settings.OutboundStore != nil {
+		s.outbound = newOutbound(settings.OutboundStore)
+	}
+	return s
+}
+
+// closed handles closing of underlying one, which is rebound unless closed explicitly.
+// It wraps OnClosed of settings.
+func (s *session) closed(state State) {
+	if s.rebinder.policy == nil {
+		if s.onClosed != nil {
+			s.onClosed(state)
+		}
+		return
+	}
+
+	if state != ExplicitClosing {
+		if s.onClosed != nil {
+			s.onClosed(state)
+		}
+		s.rebind()
+	}
+}
+
+// onResponse returns callback acknowledging keyed PDU(s), nil unless OutboundStore is set.
+func (s *session) onResponse() func(req, resp pdu.PDU) {
+	if s.outbound == nil {
+		return nil
+	}
+	return s.outbound.responded
+}
+
+func (s *session) connect(ctx context.Context, auth Auth, onFail ErrorCallback) (*Connection, error) {
+	return connect(ctx, s.dialer, auth, newBindRequest(auth, s.bindingType), onFail)
+}
+
+// start binds the first connection within ctx, using create to wrap it.
+func (s *session) start(ctx context.Context, create func(*Connection) io.Closer) (err error) {
+	s.create = create
+
+	conn, err := s.connect(ctx, s.auth, nil)
+	if err == nil {
+		s.bound(conn)
+
+		if s.auth.FailbackInterval > 0 {
+			go s.failback()
+		}
+	}
+	return
+}
+
+func (s *session) bound(conn *Connection) {
+	r := s.create(conn)
+	s.r.Store(r)
+	s.endpoint.Store(conn.Endpoint())
+
+	// session might be closed while binding, new one must not outlive it
+	if atomic.LoadInt32(&s.state) != 0 {
+		_ = r.Close()
+		return
+	}
+
+	// replay unacknowledged PDU(s) through new binding
+	if t, ok := r.(Transmitter); ok && s.outbound != nil {
+		go s.outbound.replay(t)
+	}
+}
+
+func (s *session) current() (r io.Closer) {
+	r, _ = s.r.Load().(io.Closer)
+	return
+}
+
+// submitKeyed stores PDU with client message key in OutboundStore, then submits it.
+func (s *session) submitKeyed(key string, p pdu.PDU) error {
+	if s.outbound == nil {
+		return ErrNoOutboundStore
+	}
+
+	t, ok := s.current().(Transmitter)
+	if !ok {
+		return ErrTransmitterClosing
+	}
+	return s.outbound.submit(t, key, p)
+}
+
+// Close session.
+func (s *session) Close() (err error) {
+	if atomic.CompareAndSwapInt32(&s.state, 0, 1) {
+		// stop rebinding and failback
+		s.rebinder.close()
+
+		// close underlying one
+		err = s.close()
+	}
+	return
+}
+
+// Shutdown session gracefully.
+func (s *session) Shutdown(ctx context.Context) (err error) {
+	if atomic.CompareAndSwapInt32(&s.state, 0, 1) {
+		// stop rebinding and failback
+		s.rebinder.close()
+
+		// shutdown underlying one
+		if r, ok := s.current().(interface{ Shutdown(context.Context) error }); ok {
+			err = r.Shutdown(ctx)
+		} else {
+			err = s.close()
+		}
+	}
+	return
+}
+
+// close underlying one
+func (s *session) close() (err error) {
+	if r := s.current(); r != nil {
+		err = r.Close()
+	}
+	return
+}
+
+func (s *session) rebind() {
+	if atomic.CompareAndSwapInt32(&s.rebinding, 0, 1) {
+		// close underlying one
+		_ = s.close()
+
+		rebound := s.rebinder.run(func() error {
+			conn, err := s.connect(context.Background(), s.auth, s.rebinder.onError)
+			if err == nil {
+				s.bound(conn)
+			}
+			return err
+		})
+
+		// session is closed after giving up
+		if !rebound && atomic.CompareAndSwapInt32(&s.state, 0, 1) {
+			s.rebinder.close()
+		}
+
+		// reset rebinding state
+		atomic.StoreInt32(&s.rebinding, 0)
+	}
+}
+
+// failback periodically tries to bind SMSC addresses which are preferred over the current one.
+func (s *session) failback() {
+	ticker := time.NewTicker(s.auth.FailbackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.rebinder.closed:
+			return
+
+		case <-ticker.C:
+		}
+
+		// preferred addresses precede the current one
+		preferred := s.auth.endpoints()
+		current, _ := s.endpoint.Load().(string)
+		for i, endpoint := range preferred {
+			if endpoint == current {
+				preferred = preferred[:i]
+				break
+			}
+		}
+
+		if len(preferred) == 0 || atomic.LoadInt32(&s.rebinding) != 0 {
+			continue
+		}
+
+		auth := s.auth
+		auth.SMSC, auth.Failover, auth.Resolver = preferred[0], preferred[1:], nil
+
+		conn, err := s.connect(context.Background(), auth, nil)
+		if err != nil {
+			continue
+		}
+
+		// rebinding or closing has taken over meanwhile
+		if atomic.LoadInt32(&s.state) != 0 || !atomic.CompareAndSwapInt32(&s.rebinding, 0, 1) {
+			_ = conn.Close()
+			continue
+		}
+
+		previous := s.current()
+		s.bound(conn)
+
+		// explicit closing does not trigger rebinding
+		if previous != nil {
+			_ = previous.Close()
+		}
+
+		atomic.StoreInt32(&s.rebinding, 0)
+	}
+}
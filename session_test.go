@@ -0,0 +1,155 @@
+package gosmpp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/smpptest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// deadAddr returns address which refuses connections.
+func deadAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	_ = l.Close()
+	return l.Addr().String()
+}
+
+func TestFailover(t *testing.T) {
+	t.Run("connect", func(t *testing.T) {
+		dead := deadAddr(t)
+
+		auth := nextAuth()
+		auth.SMSC, auth.Failover = dead, []string{smsc.Addr()}
+
+		conn, err := ConnectAsTransmitter(NonTLSDialer, auth)
+		require.Nil(t, err)
+		require.Equal(t, smsc.Addr(), conn.Endpoint())
+		_ = conn.Close()
+
+		// error of the last tried address is returned
+		auth.SMSC, auth.Failover = smsc.Addr(), []string{dead}
+		auth.Password = "wrong"
+
+		_, err = ConnectAsTransmitter(NonTLSDialer, auth)
+		var endpointErr *EndpointError
+		require.True(t, errors.As(err, &endpointErr))
+		require.Equal(t, dead, endpointErr.Endpoint)
+
+		auth.Resolver = func() []string { return nil }
+		_, err = ConnectAsTransmitter(NonTLSDialer, auth)
+		require.Equal(t, ErrNoEndpoint, err)
+	})
+
+	t.Run("failback", func(t *testing.T) {
+		accounts := make(map[string]string)
+		for _, pair := range auths {
+			accounts[pair[0]] = pair[1]
+		}
+
+		primarySMSC, err := smpptest.NewSMSC(smpptest.Settings{Accounts: accounts})
+		require.Nil(t, err)
+		defer func() {
+			_ = primarySMSC.Close()
+		}()
+
+		// primary is down at first
+		var primary atomic.Value
+		primary.Store(deadAddr(t))
+
+		auth := nextAuth()
+		auth.Resolver = func() []string {
+			return []string{primary.Load().(string), smsc.Addr()}
+		}
+		auth.FailbackInterval = 50 * time.Millisecond
+
+		var failures int32
+		session, err := NewTransceiverSession(NonTLSDialer, auth, TransceiveSettings{
+			EnquireLink: 200 * time.Millisecond,
+			OnRebindingError: func(err error) {
+				atomic.AddInt32(&failures, 1)
+			},
+		}, 0)
+		require.Nil(t, err)
+		defer func() {
+			_ = session.Close()
+		}()
+		require.Equal(t, smsc.Addr(), session.Endpoint())
+
+		// primary is up again
+		primary.Store(primarySMSC.Addr())
+
+		require.Eventually(t, func() bool {
+			return session.Endpoint() == primarySMSC.Addr()
+		}, 2*time.Second, 10*time.Millisecond)
+		require.Len(t, primarySMSC.Server().Sessions(), 1)
+
+		// bound transceiver works
+		require.Nil(t, session.Transceiver().Submit(newSubmitSM(auth.SystemID)))
+		_, err = primarySMSC.Wait(data.SUBMIT_SM, 1, time.Second)
+		require.Nil(t, err)
+
+		// failback does not count as rebinding
+		require.Zero(t, session.RebindAttempts())
+		require.Zero(t, atomic.LoadInt32(&failures))
+	})
+}
+
+// trackedConn records closing of underlying connection.
+type trackedConn struct {
+	net.Conn
+	closed int32
+}
+
+func (c *trackedConn) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return c.Conn.Close()
+}
+
+func TestSessionCloseWhileRebinding(t *testing.T) {
+	var (
+		dials   int32
+		dialing = make(chan struct{})
+		release = make(chan struct{})
+		rebound = make(chan *trackedConn, 1)
+	)
+
+	// the first dial binds right away, the next one waits until released
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		conn, err := NonTLSDialer(ctx, addr)
+		if err != nil || atomic.AddInt32(&dials, 1) == 1 {
+			return conn, err
+		}
+
+		close(dialing)
+		<-release
+
+		tracked := &trackedConn{Conn: conn}
+		rebound <- tracked
+		return tracked, nil
+	}
+
+	session, err := NewTransceiverSession(dialer, nextAuth(), TransceiveSettings{}, 10*time.Millisecond)
+	require.Nil(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		session.rebind()
+		close(done)
+	}()
+
+	<-dialing
+	require.Nil(t, session.Close())
+	close(release)
+	<-done
+
+	// connection bound after closing is closed as well
+	require.EqualValues(t, 1, atomic.LoadInt32(&(<-rebound).closed))
+}
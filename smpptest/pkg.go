@@ -0,0 +1,43 @@
+// Package smpptest provides an in-process SMSC for testing SMPP clients hermetically.
+//
+// SMSC listens on loopback interface, records every received PDU and responds
+// with default responses. Tests could script responses (status codes, delays,
+// dropped responses, unbinds) and inject MO messages or delivery receipts to bound ESME(s).
+package smpptest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+)
+
+var (
+	// ErrWaitTimeout indicates expected PDU(s) are not received in time.
+	ErrWaitTimeout = fmt.Errorf("Timeout waiting for PDU(s)")
+)
+
+// Reply describes how SMSC replies to a request from ESME.
+type Reply struct {
+	// Status is command status of response.
+	//
+	// Default: ESME_ROK
+	Status data.CommandStatusType
+
+	// Delay postpones the response.
+	Delay time.Duration
+
+	// Drop indicates SMSC does not respond at all.
+	Drop bool
+
+	// Unbind indicates SMSC sends unbind to ESME after responding.
+	Unbind bool
+
+	// Response overrides default response, e.g. with generic_nack.
+	// Sequence number is copied from request.
+	Response pdu.PDU
+}
+
+// Script decides reply for a request. Returning nil means default reply.
+type Script func(systemID string, p pdu.PDU) *Reply
@@ -0,0 +1,316 @@
+package smpptest
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/server"
+)
+
+const (
+	// DefaultSystemID is default system id of SMSC.
+	DefaultSystemID = "smpptest"
+)
+
+// Settings is settings for SMSC.
+type Settings struct {
+	// SystemID identifies SMSC, returned to ESME within bind_resp.
+	//
+	// Default: DefaultSystemID
+	SystemID string
+
+	// Accounts maps system id to password of accepted ESME(s).
+	//
+	// Nil Accounts accepts all binds.
+	Accounts map[string]string
+
+	// DeliveryReceipts indicates SMSC delivers a receipt for each accepted submit_sm
+	// which requests one (registered_delivery). Receipts of submits from transmitter are
+	// delivered to receiver or transceiver bound with the same system id, if any.
+	DeliveryReceipts bool
+}
+
+// SMSC is an in-process SMSC, listening on loopback interface.
+type SMSC struct {
+	settings Settings
+	server   *server.Server
+	addr     string
+
+	lock     sync.Mutex
+	received []pdu.PDU
+	changed  chan struct{}
+	script   Script
+	replies  []Reply
+}
+
+// NewSMSC starts new SMSC on random loopback port.
+func NewSMSC(settings Settings) (s *SMSC, err error) {
+	if settings.SystemID == "" {
+		settings.SystemID = DefaultSystemID
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return
+	}
+
+	s = &SMSC{
+		settings: settings,
+		addr:     l.Addr().String(),
+		changed:  make(chan struct{}),
+	}
+	s.server = server.NewServer(server.Settings{
+		SystemID:      settings.SystemID,
+		Authenticator: s.authenticate,
+		OnPDU:         s.handle,
+		OnReceive:     s.record,
+	})
+
+	go func() {
+		_ = s.server.Serve(l)
+	}()
+
+	return
+}
+
+// Addr returns listening address of SMSC.
+func (s *SMSC) Addr() string {
+	return s.addr
+}
+
+// SystemID returns system id of SMSC.
+func (s *SMSC) SystemID() string {
+	return s.settings.SystemID
+}
+
+// Server returns underlying server.
+func (s *SMSC) Server() *server.Server {
+	return s.server
+}
+
+// Received returns all PDU(s) received from ESME(s), in order.
+func (s *SMSC) Received() []pdu.PDU {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]pdu.PDU(nil), s.received...)
+}
+
+// ReceivedOf returns received PDU(s) with given command id, in order.
+func (s *SMSC) ReceivedOf(commandID data.CommandIDType) []pdu.PDU {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.filter(commandID)
+}
+
+// Wait until at least n PDU(s) with given command id are received.
+// Returns received PDU(s) with ErrWaitTimeout if they are not received in time.
+func (s *SMSC) Wait(commandID data.CommandIDType, n int, timeout time.Duration) ([]pdu.PDU, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.lock.Lock()
+		received, changed := s.filter(commandID), s.changed
+		s.lock.Unlock()
+
+		if len(received) >= n {
+			return received, nil
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return received, ErrWaitTimeout
+		}
+	}
+}
+
+// Reset clears recorded PDU(s), script and queued replies.
+func (s *SMSC) Reset() {
+	s.lock.Lock()
+	s.received, s.script, s.replies = nil, nil, nil
+	s.lock.Unlock()
+}
+
+// SetScript sets script, deciding reply for requests which are not handled by queued replies.
+func (s *SMSC) SetScript(script Script) {
+	s.lock.Lock()
+	s.script = script
+	s.lock.Unlock()
+}
+
+// ReplyNext queues replies, applied to next requests in order.
+// EnquireLink, Bind and Unbind are always handled automatically.
+func (s *SMSC) ReplyNext(replies ...Reply) {
+	s.lock.Lock()
+	s.replies = append(s.replies, replies...)
+	s.lock.Unlock()
+}
+
+// Deliver PDU to ESME, bound with systemID as receiver or transceiver.
+func (s *SMSC) Deliver(systemID string, p pdu.PDU) error {
+	return s.server.Deliver(systemID, p)
+}
+
+// Unbind requests all sessions, bound with systemID, to unbind.
+func (s *SMSC) Unbind(systemID string) (err error) {
+	err = server.ErrNoSession
+	for _, sess := range s.server.Sessions() {
+		if sess.SystemID() == systemID {
+			if err = sess.Unbind(); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// Close SMSC and all sessions.
+func (s *SMSC) Close() error {
+	return s.server.Close()
+}
+
+func (s *SMSC) authenticate(systemID, password, _ string, _ pdu.AddressRange) data.CommandStatusType {
+	if s.settings.Accounts == nil {
+		return data.ESME_ROK
+	}
+
+	expected, ok := s.settings.Accounts[systemID]
+	if !ok {
+		return data.ESME_RINVSYSID
+	}
+
+	if expected != password {
+		return data.ESME_RINVPASWD
+	}
+
+	return data.ESME_ROK
+}
+
+func (s *SMSC) record(_ *server.Session, p pdu.PDU) {
+	s.lock.Lock()
+	s.received = append(s.received, p)
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.lock.Unlock()
+}
+
+func (s *SMSC) filter(commandID data.CommandIDType) (filtered []pdu.PDU) {
+	for _, p := range s.received {
+		if p.GetHeader().CommandID == commandID {
+			filtered = append(filtered, p)
+		}
+	}
+	return
+}
+
+func (s *SMSC) nextReply(systemID string, p pdu.PDU) (reply Reply) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.replies) > 0 {
+		reply, s.replies = s.replies[0], s.replies[1:]
+		return
+	}
+
+	if s.script != nil {
+		if r := s.script(systemID, p); r != nil {
+			reply = *r
+		}
+	}
+
+	return
+}
+
+func (s *SMSC) handle(sess *server.Session, p pdu.PDU) pdu.PDU {
+	if !p.CanResponse() {
+		return nil
+	}
+
+	reply := s.nextReply(sess.SystemID(), p)
+
+	var resp pdu.PDU
+	switch {
+	case reply.Drop:
+
+	case reply.Response != nil:
+		resp = reply.Response
+		resp.SetSequenceNumber(p.GetSequenceNumber())
+
+	default:
+		resp = s.server.DefaultResponse(p)
+		resp.SetCommandStatus(reply.Status)
+	}
+
+	var receipt pdu.PDU
+	if s.settings.DeliveryReceipts {
+		if submitSM, ok := p.(*pdu.SubmitSM); ok && submitSM.RegisteredDelivery&data.SM_SMSC_RECEIPT_MASK != data.SM_SMSC_RECEIPT_NOT_REQUESTED {
+			if submitSMResp, ok := resp.(*pdu.SubmitSMResp); ok && submitSMResp.IsOk() {
+				receipt = NewReceipt(submitSM, submitSMResp.MessageID, "DELIVRD")
+			}
+		}
+	}
+
+	if reply.Delay <= 0 && !reply.Unbind && receipt == nil {
+		return resp
+	}
+
+	go func() {
+		time.Sleep(reply.Delay)
+
+		if resp != nil {
+			_ = sess.Send(resp)
+		}
+
+		if receipt != nil {
+			if sess.CanReceive() {
+				_ = sess.Deliver(receipt)
+			} else {
+				// transmitter gets its receipts through receiver bound with the same system id
+				_ = s.server.Deliver(sess.SystemID(), receipt)
+			}
+		}
+
+		if reply.Unbind {
+			_ = sess.Unbind()
+		}
+	}()
+
+	return nil
+}
+
+// NewMO returns deliver_sm carrying mobile originated message.
+func NewMO(source, dest pdu.Address, message pdu.ShortMessage) *pdu.DeliverSM {
+	deliverSM := pdu.NewDeliverSM().(*pdu.DeliverSM)
+	deliverSM.SourceAddr = source
+	deliverSM.DestAddr = dest
+	deliverSM.Message = message
+	return deliverSM
+}
+
+// NewReceipt returns deliver_sm carrying delivery receipt of submitted message with final state stat
+// (DELIVRD, EXPIRED, UNDELIV, REJECTD, ...).
+func NewReceipt(submitSM *pdu.SubmitSM, messageID, stat string) *pdu.DeliverSM {
+	now := time.Now().Format(pdu.ReceiptDateFormat)
+
+	dlvrd, errCode := "001", "000"
+	if stat != "DELIVRD" {
+		dlvrd, errCode = "000", "001"
+	}
+
+	deliverSM := pdu.NewDeliverSM().(*pdu.DeliverSM)
+	deliverSM.SourceAddr = submitSM.DestAddr
+	deliverSM.DestAddr = submitSM.SourceAddr
+	deliverSM.EsmClass = data.SM_SMSC_DLV_RCPT_TYPE
+	_ = deliverSM.Message.SetMessageWithEncoding(
+		fmt.Sprintf("id:%s sub:001 dlvrd:%s submit date:%s done date:%s stat:%s err:%s text:", messageID, dlvrd, now, now, stat, errCode),
+		data.ASCII,
+	)
+	return deliverSM
+}
@@ -0,0 +1,171 @@
+package smpptest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linxGnu/gosmpp"
+	"github.com/linxGnu/gosmpp/data"
+	"github.com/linxGnu/gosmpp/pdu"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMSC(t *testing.T) {
+	smsc, err := NewSMSC(Settings{
+		Accounts:         map[string]string{"esme": "secret"},
+		DeliveryReceipts: true,
+	})
+	require.Nil(t, err)
+	defer func() {
+		_ = smsc.Close()
+	}()
+
+	auth := gosmpp.Auth{SMSC: smsc.Addr(), SystemID: "esme", Password: "secret"}
+
+	t.Run("authFailure", func(t *testing.T) {
+		_, err := gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, gosmpp.Auth{SMSC: smsc.Addr(), SystemID: "esme", Password: "wrong"})
+		require.NotNil(t, err)
+
+		_, err = gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, gosmpp.Auth{SMSC: smsc.Addr(), SystemID: "unknown"})
+		require.NotNil(t, err)
+	})
+
+	t.Run("scripted", func(t *testing.T) {
+		smsc.Reset()
+
+		conn, err := gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		delivered := make(chan *pdu.DeliverSM, 2)
+		trans := gosmpp.NewTransceiver(conn, gosmpp.TransceiveSettings{
+			ReadTimeout: time.Second,
+			OnPDU: func(p pdu.PDU, responded bool) {
+				if pd, ok := p.(*pdu.DeliverSM); ok {
+					delivered <- pd
+				}
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+		require.Equal(t, DefaultSystemID, trans.SystemID())
+
+		smsc.ReplyNext(
+			Reply{Status: data.ESME_RTHROTTLED},
+			Reply{Drop: true},
+			Reply{Response: pdu.NewGenericNack()},
+			Reply{Delay: 50 * time.Millisecond},
+		)
+
+		// scripted status
+		resp, err := trans.SubmitAndWait(context.Background(), pdu.NewSubmitSM())
+		require.Nil(t, err)
+		require.Equal(t, data.ESME_RTHROTTLED, resp.GetHeader().CommandStatus)
+
+		// dropped
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		_, err = trans.SubmitAndWait(ctx, pdu.NewSubmitSM())
+		cancel()
+		require.Equal(t, context.DeadlineExceeded, err)
+
+		// generic_nack
+		_, err = trans.SubmitAndWait(context.Background(), pdu.NewSubmitSM())
+		require.Equal(t, gosmpp.ErrGenericNack, err)
+
+		// delayed
+		start := time.Now()
+		resp, err = trans.SubmitAndWait(context.Background(), pdu.NewSubmitSM())
+		require.Nil(t, err)
+		require.True(t, resp.IsOk())
+		require.True(t, time.Since(start) >= 50*time.Millisecond)
+
+		// script
+		smsc.SetScript(func(systemID string, p pdu.PDU) *Reply {
+			require.Equal(t, "esme", systemID)
+			if _, ok := p.(*pdu.QuerySM); ok {
+				return &Reply{Status: data.ESME_RINVMSGID}
+			}
+			return nil
+		})
+		resp, err = trans.SubmitAndWait(context.Background(), pdu.NewQuerySM())
+		require.Nil(t, err)
+		require.Equal(t, data.ESME_RINVMSGID, resp.GetHeader().CommandStatus)
+
+		// delivery receipt
+		submitSM := pdu.NewSubmitSM().(*pdu.SubmitSM)
+		submitSM.RegisteredDelivery = data.SM_SMSC_RECEIPT_REQUESTED
+		resp, err = trans.SubmitAndWait(context.Background(), submitSM)
+		require.Nil(t, err)
+
+		select {
+		case receipt := <-delivered:
+			require.True(t, receipt.IsDeliveryReceipt())
+			dr, err := receipt.DeliveryReceipt()
+			require.Nil(t, err)
+			require.Equal(t, resp.(*pdu.SubmitSMResp).MessageID, dr.MessageID)
+			require.EqualValues(t, data.SM_STATE_DELIVERED, dr.State)
+		case <-time.After(time.Second):
+			t.Fatal("delivery receipt is not received")
+		}
+
+		// MO
+		message, err := pdu.NewShortMessage("hello")
+		require.Nil(t, err)
+		require.Nil(t, smsc.Deliver("esme", NewMO(pdu.NewAddress(), pdu.NewAddress(), message)))
+
+		select {
+		case mo := <-delivered:
+			text, err := mo.Message.GetMessage()
+			require.Nil(t, err)
+			require.Equal(t, "hello", text)
+		case <-time.After(time.Second):
+			t.Fatal("MO is not received")
+		}
+
+		// recorded
+		submits, err := smsc.Wait(data.SUBMIT_SM, 5, time.Second)
+		require.Nil(t, err)
+		require.Len(t, submits, 5)
+		require.Len(t, smsc.ReceivedOf(data.QUERY_SM), 1)
+		require.Len(t, smsc.ReceivedOf(data.BIND_TRANSCEIVER), 1)
+		require.Equal(t, data.BIND_TRANSCEIVER, smsc.Received()[0].GetHeader().CommandID)
+
+		_, err = smsc.Wait(data.CANCEL_SM, 1, 50*time.Millisecond)
+		require.Equal(t, ErrWaitTimeout, err)
+	})
+
+	t.Run("unbind", func(t *testing.T) {
+		smsc.Reset()
+
+		conn, err := gosmpp.ConnectAsTransceiver(gosmpp.NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		closed := make(chan gosmpp.State, 1)
+		trans := gosmpp.NewTransceiver(conn, gosmpp.TransceiveSettings{
+			ReadTimeout: time.Second,
+			OnClosed: func(state gosmpp.State) {
+				closed <- state
+			},
+		})
+		defer func() {
+			_ = trans.Close()
+		}()
+
+		smsc.ReplyNext(Reply{Unbind: true})
+		resp, err := trans.SubmitAndWait(context.Background(), pdu.NewSubmitSM())
+		require.Nil(t, err)
+		require.True(t, resp.IsOk())
+
+		select {
+		case state := <-closed:
+			require.Equal(t, gosmpp.UnbindClosing, state)
+		case <-time.After(time.Second):
+			t.Fatal("unbind is not handled")
+		}
+
+		_, err = smsc.Wait(data.UNBIND_RESP, 1, time.Second)
+		require.Nil(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package gosmpp
 
 import (
+	"context"
 	"sync/atomic"
 	"time"
 
@@ -13,20 +14,68 @@ type TransceiveSettings struct {
 	WriteTimeout time.Duration
 
 	// ReadTimeout is timeout for reading PDU from SMSC.
-	// Underlying net.Conn will be stricted with ReadDeadline(now + timeout).
-	// This setting is very important to detect connection failure.
 	//
 	// Default: 2 secs
 	ReadTimeout time.Duration
 
+	// IdleTimeout is maximum duration that nothing is read from SMSC. When it elapses,
+	// enquire_link is sent to check if link is still alive.
+	//
+	// Default: 1 minute
+	IdleTimeout time.Duration
+
 	// EnquireLink periodically sends EnquireLink to SMSC.
 	// Zero duration means disable auto enquire link.
 	EnquireLink time.Duration
 
+	// EnquireLinkTimeout is maximum duration to wait for enquire_link_resp.
+	// Enquire link which is not responded in time is counted as missed.
+	//
+	// Default: 5 secs
+	EnquireLinkTimeout time.Duration
+
+	// EnquireLinkMaxMissed is number of consecutive missed enquire_link(s),
+	// after which link is considered dead and closed with ConnectionIssue.
+	//
+	// Default: 3
+	EnquireLinkMaxMissed int
+
+	// WindowSize is maximum number of outstanding requests, which are submitted
+	// but not responded by SMSC yet.
+	//
+	// Zero means no limit.
+	WindowSize int
+
+	// WindowWait is maximum duration that Submit waits for a free slot when window is full.
+	//
+	// Zero duration makes Submit fail fast with ErrWindowFull.
+	// Negative duration makes Submit wait until a slot is released.
+	WindowWait time.Duration
+
+	// ResponseTimeout is maximum duration to wait for response of submitted request, since it is
+	// written to connection. Expired request releases its slot in window.
+	//
+	// Every request expecting response is tracked until it is responded or expired.
+	//
+	// Default: 1 minute
+	ResponseTimeout time.Duration
+
+	// OnResponseTimeout notifies request which is not responded by SMSC within ResponseTimeout.
+	// Requests which are retried by RetryPolicy are not notified.
+	OnResponseTimeout PDUErrorCallback
+
+	// RetryPolicy re-submits requests which are rejected transiently or not responded in time.
+	RetryPolicy RetryPolicy
+
+	// RateLimit limits number of PDU(s) sent to SMSC per second.
+	RateLimit RateLimit
+
 	// OnPDU handles received PDU from SMSC.
 	//
 	// `Responded` flag indicates this pdu is responded automatically,
 	// no manual respond needed.
+	//
+	// Responses of requests submitted with SubmitAndWait are not passed to this callback.
 	OnPDU PDUCallback
 
 	// OnSubmitError notifies fail-to-submit PDU with along error.
@@ -36,11 +85,86 @@ type TransceiveSettings struct {
 	// from SMSC.
 	OnReceivingError ErrorCallback
 
+	// OnRequest handles received requests (deliver_sm, data_sm, ...) which are responded
+	// manually, through Responder. If set, requests are neither responded automatically nor
+	// passed to OnPDU. EnquireLink, Unbind and PDU(s) with unknown command id are always
+	// responded automatically.
+	OnRequest RequestCallback
+
 	// OnRebindingError notifies error while rebinding.
 	OnRebindingError ErrorCallback
 
+	// RebindingBackoff decides delays between rebinding attempts of session.
+	//
+	// Default: ConstantBackoff with rebinding interval of session.
+	RebindingBackoff BackoffPolicy
+
+	// OnRebindingGaveUp notifies session gave up rebinding, as RebindingBackoff decided.
+	// Session is closed afterwards.
+	OnRebindingGaveUp GiveUpCallback
+
 	// OnClosed notifies `closed` event due to State.
 	OnClosed ClosedCallback
+
+	// OutboundStore holds PDU(s) submitted through TransceiverSession.SubmitKeyed until they are
+	// finally responded, replaying unacknowledged ones after rebinding or restarting.
+	OutboundStore OutboundStore
+
+	// onResponse is notified once request is settled. Nil resp means it is given up without response.
+	onResponse func(req, resp pdu.PDU)
+}
+
+// session returns settings shared with session.
+func (s *TransceiveSettings) session() sessionSettings {
+	return sessionSettings{
+		RebindingBackoff:  s.RebindingBackoff,
+		OnRebindingError:  s.OnRebindingError,
+		OnRebindingGaveUp: s.OnRebindingGaveUp,
+		OnClosed:          s.OnClosed,
+		OutboundStore:     s.OutboundStore,
+	}
+}
+
+// transmit returns settings for transmitter half.
+func (s *TransceiveSettings) transmit() TransmitSettings {
+	return TransmitSettings{
+		Timeout:              s.WriteTimeout,
+		IdleTimeout:          s.IdleTimeout,
+		EnquireLink:          s.EnquireLink,
+		EnquireLinkTimeout:   s.EnquireLinkTimeout,
+		EnquireLinkMaxMissed: s.EnquireLinkMaxMissed,
+		WindowSize:           s.WindowSize,
+		WindowWait:           s.WindowWait,
+		ResponseTimeout:      s.ResponseTimeout,
+		OnResponseTimeout:    s.OnResponseTimeout,
+		RetryPolicy:          s.RetryPolicy,
+		RateLimit:            s.RateLimit,
+		OnPDU:                s.OnPDU,
+		OnSubmitError:        s.OnSubmitError,
+		OnRebindingError:     s.OnRebindingError,
+		RebindingBackoff:     s.RebindingBackoff,
+		OnRebindingGaveUp:    s.OnRebindingGaveUp,
+		OnClosed:             s.OnClosed,
+		OutboundStore:        s.OutboundStore,
+		onResponse:           s.onResponse,
+	}
+}
+
+// receive returns settings for receiver half.
+func (s *TransceiveSettings) receive() ReceiveSettings {
+	return ReceiveSettings{
+		Timeout:              s.ReadTimeout,
+		IdleTimeout:          s.IdleTimeout,
+		EnquireLinkTimeout:   s.EnquireLinkTimeout,
+		EnquireLinkMaxMissed: s.EnquireLinkMaxMissed,
+		OnPDU:                s.OnPDU,
+		OnReceivingError:     s.OnReceivingError,
+		OnRequest:            s.OnRequest,
+		OnRebindingError:     s.OnRebindingError,
+		RebindingBackoff:     s.RebindingBackoff,
+		OnRebindingGaveUp:    s.OnRebindingGaveUp,
+		OnClosed:             s.OnClosed,
+	}
 }
 
 type transceiver struct {
@@ -48,67 +172,90 @@ type transceiver struct {
 	conn     *Connection
 	in       *receiver
 	out      *transmitter
+	shutdown int32
 	state    int32
 }
 
 // NewTransceiver creates new Transceiver from bound connection.
 func NewTransceiver(conn *Connection, settings TransceiveSettings) Transceiver {
+	return newTransceiver(conn, settings)
+}
+
+func newTransceiver(conn *Connection, settings TransceiveSettings) *transceiver {
 	t := &transceiver{
 		settings: settings,
 		conn:     conn,
 	}
 
-	t.out = newTransmitter(conn, TransmitSettings{
-		Timeout: settings.WriteTimeout,
-
-		EnquireLink: settings.EnquireLink,
-
-		OnSubmitError: settings.OnSubmitError,
+	out := settings.transmit()
+	out.OnClosed = func(state State) {
+		// closing is notified by shutdown
+		if atomic.LoadInt32(&t.shutdown) != 0 {
+			return
+		}
 
-		OnClosed: func(state State) {
-			switch state {
-			case ExplicitClosing:
-				return
+		switch state {
+		case ExplicitClosing:
+			return
 
-			case ConnectionIssue:
-				// also close input
-				_ = t.in.Close()
+		case ConnectionIssue:
+			// also close input
+			_ = t.in.Close()
 
-				if t.settings.OnClosed != nil {
-					t.settings.OnClosed(ConnectionIssue)
-				}
+			if t.settings.OnClosed != nil {
+				t.settings.OnClosed(ConnectionIssue)
 			}
-		},
-	}, false)
-
-	t.in = newReceiver(conn, ReceiveSettings{
-		Timeout: settings.ReadTimeout,
+		}
+	}
+	t.out = newTransmitter(conn, out, false)
 
-		OnPDU: settings.OnPDU,
+	in := settings.receive()
+	in.OnPDU = func(p pdu.PDU, responded bool) {
+		if !t.out.handleResponse(p) && t.settings.OnPDU != nil {
+			t.settings.OnPDU(p, responded)
+		}
+	}
+	in.OnClosed = func(state State) {
+		switch state {
+		case ExplicitClosing:
+			return
+
+		case InvalidStreaming, UnbindClosing, ConnectionIssue:
+			// SMSC unbound already
+			if state == UnbindClosing {
+				atomic.StoreInt32(&t.out.unbound, 1)
+			}
 
-		OnReceivingError: settings.OnReceivingError,
+			// also close output, which stops draining of shutdown as well
+			_ = t.out.Close()
 
-		OnClosed: func(state State) {
-			switch state {
-			case ExplicitClosing:
+			// closing is notified by shutdown
+			if atomic.LoadInt32(&t.shutdown) != 0 {
 				return
-
-			case InvalidStreaming, UnbindClosing:
-				// also close output
-				_ = t.out.Close()
-
-				if t.settings.OnClosed != nil {
-					t.settings.OnClosed(state)
-				}
 			}
-		},
 
-		response: func(p pdu.PDU) {
-			if t.out.Submit(p) != nil { // only happened when transceiver is closed
-				_, _ = t.out.write(marshal(p))
+			if t.settings.OnClosed != nil {
+				t.settings.OnClosed(state)
 			}
-		},
-	}, false)
+		}
+	}
+	in.response = func(p pdu.PDU) (err error) {
+		// unbind_resp is sent right away, since transceiver is closed afterwards
+		if _, ok := p.(*pdu.UnbindResp); ok {
+			_, err = t.out.write(marshal(p))
+			return
+		}
+
+		if err = t.out.Submit(p); err != nil { // only happened when transceiver is closed or draining
+			_, err = t.out.write(marshal(p))
+		}
+		return
+	}
+	in.enquireLink = t.out.enquireLink
+	t.in = newReceiver(conn, in, false)
+
+	// enquire links of both halves are tracked together
+	t.out.liveness = t.in.liveness
 
 	t.out.start()
 	t.in.start()
@@ -139,7 +286,32 @@ func (t *transceiver) Close() (err error) {
 	return
 }
 
+// Shutdown transceiver gracefully: stops accepting PDU(s), waits until queued ones are sent and
+// outstanding requests are responded, then unbinds from SMSC and waits for unbind_resp before closing.
+//
+// Transceiver is closed immediately once ctx is done, returning ctx.Err().
+func (t *transceiver) Shutdown(ctx context.Context) (err error) {
+	if !atomic.CompareAndSwapInt32(&t.shutdown, 0, 1) {
+		return ErrTransmitterClosing
+	}
+
+	atomic.StoreInt32(&t.in.unbinding, 1)
+	if err = t.out.drain(ctx); err == nil {
+		err = t.out.unbind(ctx)
+	}
+
+	if e := t.Close(); err == nil {
+		err = e
+	}
+	return
+}
+
 // Submit a PDU.
 func (t *transceiver) Submit(p pdu.PDU) error {
 	return t.out.Submit(p)
 }
+
+// SubmitAndWait submits a PDU and waits for its response from SMSC.
+func (t *transceiver) SubmitAndWait(ctx context.Context, p pdu.PDU) (pdu.PDU, error) {
+	return t.out.SubmitAndWait(ctx, p)
+}
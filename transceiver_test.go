@@ -1,13 +1,16 @@
 package gosmpp
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/linxGnu/gosmpp/data"
 	"github.com/linxGnu/gosmpp/pdu"
+	"github.com/linxGnu/gosmpp/smpptest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -77,7 +80,7 @@ func TestSubmitSM(t *testing.T) {
 		_ = trans.Close()
 	}()
 
-	require.Equal(t, "MelroseLabsSMSC", trans.Transceiver().SystemID())
+	require.Equal(t, smscSystemID, trans.Transceiver().SystemID())
 
 	// sending 20 SMS
 	for i := 0; i < 20; i++ {
@@ -122,3 +125,167 @@ func newSubmitSM(systemID string) *pdu.SubmitSM {
 
 	return submitSM
 }
+
+func TestTransceiverShutdown(t *testing.T) {
+	server, err := smpptest.NewSMSC(smpptest.Settings{})
+	require.Nil(t, err)
+	defer func() {
+		_ = server.Close()
+	}()
+
+	auth := Auth{SMSC: server.Addr(), SystemID: "shutdown", Password: "shutdown"}
+
+	t.Run("graceful", func(t *testing.T) {
+		server.Reset()
+
+		conn, err := ConnectAsTransceiver(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		var (
+			responses int32
+			states    = make(chan State, 2)
+		)
+		trans := NewTransceiver(conn, TransceiveSettings{
+			WindowSize: 10,
+			OnPDU: func(p pdu.PDU, _ bool) {
+				if _, ok := p.(*pdu.SubmitSMResp); ok {
+					atomic.AddInt32(&responses, 1)
+				}
+			},
+			OnClosed: func(state State) {
+				states <- state
+			},
+		})
+
+		// outstanding request is responded before unbinding
+		server.ReplyNext(smpptest.Reply{Delay: 200 * time.Millisecond})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		require.Nil(t, trans.Shutdown(ctx))
+		require.EqualValues(t, 1, atomic.LoadInt32(&responses))
+		require.Equal(t, ErrTransmitterClosing, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		// unbind is sent once, after the request
+		received := server.Received()
+		require.Len(t, server.ReceivedOf(data.UNBIND), 1)
+		require.EqualValues(t, data.UNBIND, received[len(received)-1].GetHeader().CommandID)
+
+		require.Equal(t, ExplicitClosing, <-states)
+		require.Len(t, states, 0)
+	})
+
+	t.Run("defaultSettings", func(t *testing.T) {
+		server.Reset()
+
+		conn, err := ConnectAsTransmitter(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		var responses int32
+		trans := NewTransmitter(conn, TransmitSettings{
+			OnPDU: func(p pdu.PDU, _ bool) {
+				if _, ok := p.(*pdu.SubmitSMResp); ok {
+					atomic.AddInt32(&responses, 1)
+				}
+			},
+		})
+
+		// requests are waited for, even though neither window nor timeout is set
+		server.ReplyNext(smpptest.Reply{Delay: 200 * time.Millisecond})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		require.Nil(t, trans.Shutdown(ctx))
+		require.EqualValues(t, 1, atomic.LoadInt32(&responses))
+
+		received := server.Received()
+		require.EqualValues(t, data.UNBIND, received[len(received)-1].GetHeader().CommandID)
+	})
+
+	t.Run("linkDropped", func(t *testing.T) {
+		// SMSC responds to bind, then drops connection once submit_sm is received
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer func() {
+			_ = l.Close()
+		}()
+
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer func() {
+				_ = conn.Close()
+			}()
+
+			p, err := pdu.Parse(conn)
+			if err != nil {
+				return
+			}
+			if _, err = conn.Write(marshal(p.GetResponse())); err == nil {
+				_, _ = pdu.Parse(conn)
+				time.Sleep(200 * time.Millisecond)
+			}
+		}()
+
+		conn, err := ConnectAsTransceiver(NonTLSDialer, Auth{SMSC: l.Addr().String(), SystemID: "drop", Password: "drop"})
+		require.Nil(t, err)
+
+		trans := NewTransceiver(conn, TransceiveSettings{})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		// draining is stopped, although request never expires
+		done := make(chan error, 1)
+		go func() {
+			done <- trans.Shutdown(context.Background())
+		}()
+
+		select {
+		case err := <-done:
+			require.Equal(t, ErrTransmitterClosing, err)
+
+		case <-time.After(2 * time.Second):
+			t.Fatal("shutdown hangs after link is dropped")
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		server.Reset()
+
+		conn, err := ConnectAsTransceiver(NonTLSDialer, auth)
+		require.Nil(t, err)
+
+		trans := NewTransceiver(conn, TransceiveSettings{WindowSize: 10})
+
+		server.ReplyNext(smpptest.Reply{Drop: true})
+		require.Nil(t, trans.Submit(newSubmitSM(auth.SystemID)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		require.Equal(t, context.DeadlineExceeded, trans.Shutdown(ctx))
+		require.Equal(t, ErrTransmitterClosing, trans.Submit(newSubmitSM(auth.SystemID)))
+	})
+
+	t.Run("session", func(t *testing.T) {
+		server.Reset()
+
+		session, err := NewReceiverSession(NonTLSDialer, auth, ReceiveSettings{}, 10*time.Millisecond)
+		require.Nil(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		require.Nil(t, session.Shutdown(ctx))
+		require.Len(t, server.ReceivedOf(data.UNBIND), 1)
+
+		// no rebinding after shutdown
+		time.Sleep(50 * time.Millisecond)
+		require.Zero(t, session.RebindAttempts())
+	})
+}
@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/linxGnu/gosmpp/data"
 	"github.com/linxGnu/gosmpp/pdu"
 )
 
 const (
 	// EnquireLinkIntervalMinimum represents minimum interval for enquire link.
 	EnquireLinkIntervalMinimum = 20 * time.Second
+
+	defaultResponseTimeout = time.Minute
 )
 
 var (
@@ -25,26 +29,139 @@ type TransmitSettings struct {
 	// Timeout is timeout/deadline for submitting PDU.
 	Timeout time.Duration
 
+	// IdleTimeout is maximum duration that nothing is read from SMSC. When it elapses,
+	// enquire_link is sent to check if link is still alive.
+	//
+	// Default: twice of EnquireLink duration.
+	IdleTimeout time.Duration
+
 	// EnquireLink periodically sends EnquireLink to SMSC.
 	// The duration must not be smaller than 1 minute.
 	//
 	// Zero duration disables auto enquire link.
 	EnquireLink time.Duration
 
+	// EnquireLinkTimeout is maximum duration to wait for enquire_link_resp.
+	// Enquire link which is not responded in time is counted as missed.
+	//
+	// Default: 5 secs
+	EnquireLinkTimeout time.Duration
+
+	// EnquireLinkMaxMissed is number of consecutive missed enquire_link(s),
+	// after which link is considered dead and closed with ConnectionIssue.
+	//
+	// Default: 3
+	EnquireLinkMaxMissed int
+
+	// WindowSize is maximum number of outstanding requests, which are submitted
+	// but not responded by SMSC yet.
+	//
+	// Zero means no limit.
+	WindowSize int
+
+	// WindowWait is maximum duration that Submit waits for a free slot when window is full.
+	//
+	// Zero duration makes Submit fail fast with ErrWindowFull.
+	// Negative duration makes Submit wait until a slot is released.
+	WindowWait time.Duration
+
+	// ResponseTimeout is maximum duration to wait for response of submitted request, since it is
+	// written to connection. Expired request releases its slot in window.
+	//
+	// Every request expecting response is tracked until it is responded or expired.
+	//
+	// Default: 1 minute
+	ResponseTimeout time.Duration
+
+	// OnResponseTimeout notifies request which is not responded by SMSC within ResponseTimeout.
+	// Requests which are retried by RetryPolicy are not notified.
+	OnResponseTimeout PDUErrorCallback
+
+	// RetryPolicy re-submits requests which are rejected transiently or not responded in time.
+	RetryPolicy RetryPolicy
+
+	// RateLimit limits number of PDU(s) sent to SMSC per second.
+	RateLimit RateLimit
+
+	// OnPDU handles received PDU (mostly responses) from SMSC.
+	//
+	// Responses of requests submitted with SubmitAndWait are not passed to this callback.
+	OnPDU PDUCallback
+
 	// OnSubmitError notifies fail-to-submit PDU with along error.
 	OnSubmitError PDUErrorCallback
 
 	// OnRebindingError notifies error while rebinding.
 	OnRebindingError ErrorCallback
 
+	// RebindingBackoff decides delays between rebinding attempts of session.
+	//
+	// Default: ConstantBackoff with rebinding interval of session.
+	RebindingBackoff BackoffPolicy
+
+	// OnRebindingGaveUp notifies session gave up rebinding, as RebindingBackoff decided.
+	// Session is closed afterwards.
+	OnRebindingGaveUp GiveUpCallback
+
 	// OnClosed notifies `closed` event due to State.
 	OnClosed ClosedCallback
+
+	// OutboundStore holds PDU(s) submitted through TransmitterSession.SubmitKeyed until they are
+	// finally responded, replaying unacknowledged ones after rebinding or restarting.
+	OutboundStore OutboundStore
+
+	// onResponse is notified once request is settled. Nil resp means it is given up without response.
+	onResponse func(req, resp pdu.PDU)
+}
+
+// transceive returns settings for Transceiver acting as Transmitter.
+func (s *TransmitSettings) transceive() TransceiveSettings {
+	return TransceiveSettings{
+		WriteTimeout:         s.Timeout,
+		IdleTimeout:          s.IdleTimeout,
+		EnquireLink:          s.EnquireLink,
+		EnquireLinkTimeout:   s.EnquireLinkTimeout,
+		EnquireLinkMaxMissed: s.EnquireLinkMaxMissed,
+		WindowSize:           s.WindowSize,
+		WindowWait:           s.WindowWait,
+		ResponseTimeout:      s.ResponseTimeout,
+		OnResponseTimeout:    s.OnResponseTimeout,
+		RetryPolicy:          s.RetryPolicy,
+		RateLimit:            s.RateLimit,
+		OnPDU:                s.OnPDU,
+		OnSubmitError:        s.OnSubmitError,
+		OnRebindingError:     s.OnRebindingError,
+		RebindingBackoff:     s.RebindingBackoff,
+		OnRebindingGaveUp:    s.OnRebindingGaveUp,
+		OnClosed:             s.OnClosed,
+		OutboundStore:        s.OutboundStore,
+		onResponse:           s.onResponse,
+	}
 }
 
 func (s *TransmitSettings) normalize() {
 	if s.EnquireLink <= EnquireLinkIntervalMinimum {
 		s.EnquireLink = EnquireLinkIntervalMinimum
 	}
+
+	if s.IdleTimeout <= 0 {
+		s.IdleTimeout = s.EnquireLink << 1
+	}
+
+	if s.ResponseTimeout <= 0 {
+		s.ResponseTimeout = defaultResponseTimeout
+	}
+}
+
+// session returns settings shared with session.
+func (s *TransmitSettings) session() sessionSettings {
+	return sessionSettings{
+		RebindingBackoff:  s.RebindingBackoff,
+		OnRebindingError:  s.OnRebindingError,
+		OnRebindingGaveUp: s.OnRebindingGaveUp,
+		OnClosed:          s.OnClosed,
+		OutboundStore:     s.OutboundStore,
+	}
 }
 
 type transmitter struct {
@@ -54,13 +171,26 @@ type transmitter struct {
 	settings TransmitSettings
 	conn     *Connection
 	input    chan pdu.PDU
+	order    chan struct{} // held while numbering and queuing PDU
+	pending  pendingRequests
+	limiter  *rateLimiter
+	retrier  *retrier
+	liveness *liveness
 	lock     sync.RWMutex
+	queued   int64
+	draining int32
+	unbound  int32
 	state    int32
 }
 
 // NewTransmitter returns new Transmitter.
+//
+// Transmitter also reads responses from SMSC on the same connection,
+// so that they could be matched with submitted requests.
 func NewTransmitter(conn *Connection, settings TransmitSettings) Transmitter {
-	return newTransmitter(conn, settings, true)
+	settings.normalize()
+
+	return newTransceiver(conn, settings.transceive())
 }
 
 func newTransmitter(conn *Connection, settings TransmitSettings, startDaemon bool) *transmitter {
@@ -70,9 +200,16 @@ func newTransmitter(conn *Connection, settings TransmitSettings, startDaemon boo
 		settings: settings,
 		conn:     conn,
 		input:    make(chan pdu.PDU, 1),
+		order:    make(chan struct{}, 1),
+		limiter:  newRateLimiter(settings.RateLimit),
+		retrier:  newRetrier(settings.RetryPolicy),
 	}
 	t.ctx, t.cancel = context.WithCancel(context.Background())
 
+	if settings.WindowSize > 0 {
+		t.pending.window = make(chan struct{}, settings.WindowSize)
+	}
+
 	// start transmitter daemon(s)
 	if startDaemon {
 		t.start()
@@ -105,8 +242,15 @@ func (t *transmitter) close(state State) (err error) {
 		// wait daemon
 		t.wg.Wait()
 
-		// try to send unbind
-		_, _ = t.conn.Write(marshal(pdu.NewUnbind()))
+		// fail all outstanding requests
+		t.pending.failAll(ErrTransmitterClosing)
+
+		// try to send unbind, unless unbinding is done already
+		if atomic.LoadInt32(&t.unbound) == 0 {
+			unbind := pdu.NewUnbind()
+			t.conn.assignSequenceNumber(unbind)
+			_, _ = t.conn.Write(marshal(unbind))
+		}
 
 		// close connection
 		if state != StoppingProcessOnly {
@@ -132,24 +276,194 @@ func (t *transmitter) closing(state State) {
 
 // Submit a PDU.
 func (t *transmitter) Submit(p pdu.PDU) (err error) {
+	_, err = t.submit(context.Background(), p, false, t.settings.WindowWait)
+	return
+}
+
+// SubmitAndWait submits a PDU and waits for its response from SMSC.
+//
+// Response is matched with request by sequence number. Waiting is failed when
+// ctx is done, SMSC responded with generic_nack, response timed out or transmitter is closed.
+func (t *transmitter) SubmitAndWait(ctx context.Context, p pdu.PDU) (resp pdu.PDU, err error) {
+	return t.submitAndWait(ctx, p, t.settings.WindowWait)
+}
+
+// submitAndWait submits a PDU, waiting at most windowWait for a free slot in window,
+// then waits for its response.
+func (t *transmitter) submitAndWait(ctx context.Context, p pdu.PDU, windowWait time.Duration) (resp pdu.PDU, err error) {
+	if !p.CanResponse() {
+		err = ErrNoResponse
+		return
+	}
+
+	ch, err := t.submit(ctx, p, true, windowWait)
+	if err != nil {
+		return
+	}
+
+	select {
+	case r := <-ch:
+		resp, err = r.p, r.err
+
+	case <-ctx.Done():
+		t.pending.detach(p.GetSequenceNumber())
+		err = ctx.Err()
+	}
+
+	return
+}
+
+// submit a PDU, waiting at most windowWait for a free slot in window. See WindowWait of settings.
+func (t *transmitter) submit(ctx context.Context, p pdu.PDU, wait bool, windowWait time.Duration) (ch chan response, err error) {
+	if err = p.ValidateOptionalParams(); err != nil {
+		return
+	}
+
+	// requests are tracked until responded, hence counted for window/timeout
+	// and waited for by graceful shutdown
+	track := p.CanResponse()
+
+	// requests which no one waits for are retried
+	retry := track && !wait && t.retrier != nil
+
+	// acquire window slot before locking, so that closing is not blocked
+	if track {
+		if err = t.pending.acquire(ctx, t.ctx.Done(), windowWait); err != nil {
+			return
+		}
+	}
+
 	t.lock.RLock()
 	defer t.lock.RUnlock()
 
-	if t.state == 0 {
+	// only unbind of graceful shutdown is accepted while draining
+	if t.state != 0 || (atomic.LoadInt32(&t.draining) != 0 && p.GetHeader().CommandID != data.UNBIND) {
+		if track {
+			t.pending.release()
+		}
+		err = ErrTransmitterClosing
+		return
+	}
+
+	// sequence number is stamped and PDU is queued under ordering lock,
+	// so that PDU(s) are written in order of their sequence numbers
+	select {
+	case <-t.ctx.Done():
+		err = t.ctx.Err()
+
+	case <-ctx.Done():
+		err = ctx.Err()
+
+	case t.order <- struct{}{}:
+		defer func() { <-t.order }()
+	}
+
+	if err != nil {
+		if track {
+			t.pending.release()
+		}
+		return
+	}
+
+	t.conn.assignSequenceNumber(p)
+
+	if track {
+		ch = t.pending.register(p, wait, t.settings.ResponseTimeout)
+	}
+
+	if retry {
+		t.retrier.sent(p)
+	}
+
+	atomic.AddInt64(&t.queued, 1)
+
+	select {
+	case <-t.ctx.Done():
+		err = t.ctx.Err()
+
+	case <-ctx.Done():
+		err = ctx.Err()
+
+	case t.input <- p:
+	}
+
+	if err != nil {
+		atomic.AddInt64(&t.queued, -1)
+
+		if track {
+			t.pending.remove(p.GetSequenceNumber())
+		}
+
+		if retry {
+			t.retrier.unsent(p)
+		}
+	}
+
+	return
+}
+
+// drain stops accepting PDU(s), then waits until queued ones are sent
+// and outstanding ones are responded.
+func (t *transmitter) drain(ctx context.Context) (err error) {
+	atomic.StoreInt32(&t.draining, 1)
+
+	// wait for submits in progress to queue their PDU(s)
+	t.lock.Lock()
+	closed := t.state != 0
+	t.lock.Unlock()
+
+	if closed {
+		return ErrTransmitterClosing
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for atomic.LoadInt64(&t.queued) > 0 || t.pending.inflight() > 0 {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
 		case <-t.ctx.Done():
-			err = t.ctx.Err()
+			return ErrTransmitterClosing
 
-		case t.input <- p:
+		case <-ticker.C:
 		}
-	} else {
-		err = ErrTransmitterClosing
 	}
 
 	return
 }
 
+// unbind sends unbind to SMSC and waits for unbind_resp.
+func (t *transmitter) unbind(ctx context.Context) (err error) {
+	ch, err := t.submit(ctx, pdu.NewUnbind(), true, t.settings.WindowWait)
+	if err != nil {
+		return
+	}
+
+	select {
+	case r := <-ch:
+		err = r.err
+
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err == nil {
+		atomic.StoreInt32(&t.unbound, 1)
+	}
+	return
+}
+
 func (t *transmitter) start() {
+	if t.settings.ResponseTimeout > 0 {
+		t.wg.Add(1)
+		go func() {
+			t.loopExpire()
+			t.wg.Done()
+		}()
+	}
+
 	t.wg.Add(1)
 	if t.settings.EnquireLink > 0 {
 		go func() {
@@ -168,7 +482,10 @@ func (t *transmitter) start() {
 func (t *transmitter) loop() {
 	for p := range t.input {
 		if p != nil {
+			t.limit(p)
 			n, err := t.write(marshal(p))
+			atomic.AddInt64(&t.queued, -1)
+			t.pending.written(p.GetSequenceNumber(), time.Now())
 			if t.check(p, n, err) {
 				return
 			}
@@ -187,15 +504,29 @@ func (t *transmitter) loopWithEnquireLink() {
 
 	// enquireLink payload
 	eqp := pdu.NewEnquireLink()
-	enquireLink := marshal(eqp)
 
-	for {
-		select {
-		case <-ticker.C:
-			n, err := t.write(enquireLink)
+	for due := false; ; {
+		// enquire link is numbered once no submit is in progress, otherwise
+		// the PDU being queued is written first
+		if due && t.tryOrder() {
+			due = false
+
+			t.conn.assignSequenceNumber(eqp)
+			<-t.order
+
+			t.limit(eqp)
+			if t.liveness != nil {
+				t.liveness.sent(eqp.GetSequenceNumber(), time.Now())
+			}
+			n, err := t.write(marshal(eqp))
 			if t.check(eqp, n, err) {
 				return
 			}
+		}
+
+		select {
+		case <-ticker.C:
+			due = true
 
 		case p, ok := <-t.input:
 			if !ok {
@@ -203,7 +534,10 @@ func (t *transmitter) loopWithEnquireLink() {
 			}
 
 			if p != nil {
+				t.limit(p)
 				n, err := t.write(marshal(p))
+				atomic.AddInt64(&t.queued, -1)
+				t.pending.written(p.GetSequenceNumber(), time.Now())
 				if t.check(p, n, err) {
 					return
 				}
@@ -212,6 +546,150 @@ func (t *transmitter) loopWithEnquireLink() {
 	}
 }
 
+// enquireLink queues enquire_link checking idle link, written through the same path as
+// submitted PDU(s). It is tracked by liveness before queuing, so that its response is not missed.
+func (t *transmitter) enquireLink() {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.state != 0 {
+		return
+	}
+
+	select {
+	case <-t.ctx.Done():
+		return
+
+	case t.order <- struct{}{}:
+		defer func() { <-t.order }()
+	}
+
+	p := pdu.NewEnquireLink()
+	t.conn.assignSequenceNumber(p)
+	if t.liveness != nil {
+		t.liveness.sent(p.GetSequenceNumber(), time.Now())
+	}
+
+	atomic.AddInt64(&t.queued, 1)
+
+	select {
+	case <-t.ctx.Done():
+		atomic.AddInt64(&t.queued, -1)
+
+	case t.input <- p:
+	}
+}
+
+// tryOrder acquires ordering lock, unless it is held already.
+func (t *transmitter) tryOrder() bool {
+	select {
+	case t.order <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// limit waits until PDU is allowed to send by rate limiter.
+// PDU(s) are not limited anymore when transmitter is closing.
+func (t *transmitter) limit(p pdu.PDU) {
+	if t.limiter != nil {
+		_ = t.limiter.wait(t.ctx, p)
+	}
+}
+
+// handleResponse passes response PDU to its waiter and adapts rate limit.
+// Returns true if response is consumed by waiter.
+func (t *transmitter) handleResponse(p pdu.PDU) bool {
+	if t.limiter != nil && isThrottled(p) {
+		t.limiter.throttled(time.Now())
+	}
+
+	req, resolved := t.pending.match(p)
+	if req != nil && !resolved && t.retry(req, p) {
+		return true
+	}
+
+	if req != nil {
+		t.settle(req, p)
+	}
+	return resolved
+}
+
+// settle notifies request which is finally responded, or given up without response (nil resp).
+func (t *transmitter) settle(req, resp pdu.PDU) {
+	if t.settings.onResponse != nil {
+		t.settings.onResponse(req, resp)
+	}
+}
+
+// retry request which is rejected transiently or not responded in time (nil resp), as RetryPolicy decides.
+// Returns false if request is not retried.
+func (t *transmitter) retry(p, resp pdu.PDU) bool {
+	if t.retrier == nil {
+		return false
+	}
+
+	delay, retry := t.retrier.decide(p, resp)
+	if retry {
+		go t.resubmit(p, delay)
+	}
+	return retry
+}
+
+// resubmit request after delay, regardless of WindowWait. Request which could not be re-submitted is finally failed.
+func (t *transmitter) resubmit(p pdu.PDU, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-t.ctx.Done():
+		t.retrier.abandon(p, ErrTransmitterClosing)
+		t.settle(p, nil)
+
+	case <-timer.C:
+		// retried request waits for free slot in window, until transmitter is closed
+		if _, err := t.submit(context.Background(), p, false, -1); err != nil {
+			t.retrier.abandon(p, err)
+			t.settle(p, nil)
+		}
+	}
+}
+
+// expire outstanding requests which are not responded in time
+func (t *transmitter) loopExpire() {
+	ticker := time.NewTicker(expireCheckInterval(t.settings.ResponseTimeout))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			for _, p := range t.pending.expire(now) {
+				if t.retry(p, nil) {
+					continue
+				}
+				t.settle(p, nil)
+
+				if t.settings.OnResponseTimeout != nil {
+					t.settings.OnResponseTimeout(p, ErrResponseTimeout)
+				}
+			}
+		}
+	}
+}
+
+func expireCheckInterval(timeout time.Duration) (v time.Duration) {
+	if v = timeout >> 2; v < 10*time.Millisecond {
+		v = 10 * time.Millisecond
+	} else if v > time.Second {
+		v = time.Second
+	}
+	return
+}
+
 // check error and do closing if need
 func (t *transmitter) check(p pdu.PDU, n int, err error) (closing bool) {
 	if err == nil {
@@ -222,6 +700,8 @@ func (t *transmitter) check(p pdu.PDU, n int, err error) (closing bool) {
 		t.settings.OnSubmitError(p, err)
 	}
 
+	t.pending.fail(p.GetSequenceNumber(), err)
+
 	if n == 0 {
 		if nErr, ok := err.(net.Error); ok {
 			closing = nErr.Timeout() || !nErr.Temporary()
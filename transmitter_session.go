@@ -1,24 +1,18 @@
 package gosmpp
 
 import (
-	"sync/atomic"
+	"context"
+	"io"
 	"time"
+
+	"github.com/linxGnu/gosmpp/pdu"
 )
 
 // TransmitterSession represents session for Transmitter.
 type TransmitterSession struct {
-	dialer Dialer
-	auth   Auth
-
-	originalOnClosed func(State)
-	settings         TransmitSettings
-
-	rebindingInterval time.Duration
+	*session
 
-	r atomic.Value // Transmitter
-
-	state     int32
-	rebinding int32
+	settings TransmitSettings
 }
 
 // NewTransmitterSession creates new session for Transmitter.
@@ -27,42 +21,28 @@ type TransmitterSession struct {
 // unexpected error happened.
 //
 // `rebindingInterval` indicates duration that Session has to wait before rebinding again.
+// Settings.RebindingBackoff, if set, takes precedence over it.
 //
-// Setting `rebindingInterval <= 0` will disable `auto-rebind` functionality.
+// Setting `rebindingInterval <= 0` without RebindingBackoff will disable `auto-rebind` functionality.
 func NewTransmitterSession(dialer Dialer, auth Auth, settings TransmitSettings, rebindingInterval time.Duration) (session *TransmitterSession, err error) {
-	conn, err := ConnectAsTransmitter(dialer, auth)
-	if err == nil {
-		session = &TransmitterSession{
-			dialer:            dialer,
-			auth:              auth,
-			rebindingInterval: rebindingInterval,
-			originalOnClosed:  settings.OnClosed,
-		}
-
-		if rebindingInterval > 0 {
-			newSettings := settings
-			newSettings.OnClosed = func(state State) {
-				switch state {
-				case ExplicitClosing:
-					return
-
-				default:
-					if session.originalOnClosed != nil {
-						session.originalOnClosed(state)
-					}
-					session.rebind()
-				}
-			}
-			session.settings = newSettings
-		} else {
-			session.settings = settings
-		}
-
-		// create new Transmitter
-		r := NewTransmitter(conn, session.settings)
+	return NewTransmitterSessionContext(context.Background(), dialer, auth, settings, rebindingInterval)
+}
 
-		// bind to session
-		session.r.Store(r)
+// NewTransmitterSessionContext creates new session for Transmitter, like NewTransmitterSession.
+// Dialing and binding of the first connection are canceled once context is done.
+func NewTransmitterSessionContext(ctx context.Context, dialer Dialer, auth Auth, settings TransmitSettings, rebindingInterval time.Duration) (session *TransmitterSession, err error) {
+	session = &TransmitterSession{
+		session:  newSession(dialer, auth, pdu.Transmitter, settings.session(), rebindingInterval),
+		settings: settings,
+	}
+	session.settings.OnClosed = session.closed
+	session.settings.onResponse = session.onResponse()
+
+	// create new Transmitter
+	if err = session.start(ctx, func(conn *Connection) io.Closer {
+		return NewTransmitter(conn, session.settings)
+	}); err != nil {
+		session = nil
 	}
 	return
 }
@@ -73,46 +53,38 @@ func (s *TransmitterSession) Transmitter() (r Transmitter) {
 	return
 }
 
-// Close session.
-func (s *TransmitterSession) Close() (err error) {
-	if atomic.CompareAndSwapInt32(&s.state, 0, 1) {
-		// close underlying Transmitter
-		err = s.close()
-	}
+// Endpoint returns SMSC address which session is bound to.
+func (s *TransmitterSession) Endpoint() (endpoint string) {
+	endpoint, _ = s.endpoint.Load().(string)
 	return
 }
 
-// close underlying Transmitter
-func (s *TransmitterSession) close() (err error) {
-	if r := s.Transmitter(); r != nil {
-		err = r.Close()
-	}
-	return
+// RebindAttempts returns total number of rebinding attempts made by session.
+func (s *TransmitterSession) RebindAttempts() uint64 {
+	return s.rebinder.total()
 }
 
-func (s *TransmitterSession) rebind() {
-	if atomic.CompareAndSwapInt32(&s.rebinding, 0, 1) {
-		// close underlying Transmitter
-		_ = s.close()
-
-		for atomic.LoadInt32(&s.state) == 0 {
-			conn, err := ConnectAsTransmitter(s.dialer, s.auth)
-			if err != nil {
-				if s.settings.OnRebindingError != nil {
-					s.settings.OnRebindingError(err)
-				}
-				time.Sleep(s.rebindingInterval)
-			} else {
-				r := NewTransmitter(conn, s.settings)
-
-				// bind to session
-				s.r.Store(r)
+// SubmitKeyed stores PDU with client message key in Settings.OutboundStore, then submits it.
+// PDU stays in store until SMSC finally responds to it, and is replayed after rebinding or
+// restarting with the same store, hence SMSC might receive it more than once.
+//
+// ErrDuplicateKey is returned if PDU with the same key is stored already. PDU which is stored
+// but failed to submit is replayed on next binding.
+//
+// PDU rejected temporarily (ESME_RTHROTTLED, ESME_RMSGQFUL, ESME_RX_T_APPN) is not resubmitted
+// on the current binding, unless Settings.RetryPolicy retries it. It stays in store and is
+// replayed only after rebinding or restarting. So is PDU which is not responded in time, or
+// which Settings.RetryPolicy gives up re-submitting.
+func (s *TransmitterSession) SubmitKeyed(key string, p pdu.PDU) error {
+	return s.submitKeyed(key, p)
+}
 
-				// reset rebinding state
-				atomic.StoreInt32(&s.rebinding, 0)
+// Close session.
+func (s *TransmitterSession) Close() error {
+	return s.session.Close()
+}
 
-				return
-			}
-		}
-	}
+// Shutdown session gracefully, stopping rebinding. See Transmitter.Shutdown.
+func (s *TransmitterSession) Shutdown(ctx context.Context) error {
+	return s.session.Shutdown(ctx)
 }
@@ -35,7 +35,7 @@ func TestTransmitter(t *testing.T) {
 			_ = transmitter.Close()
 		}()
 
-		require.Equal(t, "MelroseLabsSMSC", transmitter.Transmitter().SystemID())
+		require.Equal(t, smscSystemID, transmitter.Transmitter().SystemID())
 
 		err = transmitter.Transmitter().Submit(newSubmitSM(auth.SystemID))
 		require.Nil(t, err)
@@ -48,7 +48,7 @@ func TestTransmitter(t *testing.T) {
 	})
 
 	errorHandling := func(t *testing.T, trigger func(*transmitter)) {
-		conn, err := net.Dial("tcp", "smscsim.melroselabs.com:2775")
+		conn, err := net.Dial("tcp", smsc.Addr())
 		require.Nil(t, err)
 
 		var tr transmitter
@@ -13,3 +13,16 @@ type ErrorCallback func(error)
 
 // ClosedCallback notifies `closed` event due to State.
 type ClosedCallback func(State)
+
+// MessageCallback notifies reassembled inbound message.
+type MessageCallback func(*ReassembledMessage)
+
+// PartsCallback notifies parts of incomplete message.
+type PartsCallback func([]pdu.PDU)
+
+// GiveUpCallback notifies session gave up rebinding after consecutive failed attempts,
+// along with the last error.
+type GiveUpCallback func(attempts int, err error)
+
+// RequestCallback handles received request, which must be responded through Responder.
+type RequestCallback func(pdu pdu.PDU, r *Responder)
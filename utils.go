@@ -1,9 +1,11 @@
 package gosmpp
 
 import (
-	"fmt"
+	"context"
+	"io"
+	"net"
+	"time"
 
-	"github.com/linxGnu/gosmpp/data"
 	"github.com/linxGnu/gosmpp/pdu"
 )
 
@@ -13,45 +15,129 @@ func marshal(p pdu.PDU) []byte {
 	return buf.Bytes()
 }
 
-func connect(dialer Dialer, addr string, bindReq *pdu.BindRequest) (c *Connection, err error) {
-	conn, err := dialer(addr)
-	if err != nil {
+// parse PDU from reader, where TLV violating its definition is not fatal.
+func parse(r io.Reader) (p pdu.PDU, err error) {
+	if p, err = pdu.Parse(r); p != nil {
+		if _, ok := err.(*pdu.InvalidTLVError); ok {
+			err = nil
+		}
+	}
+	return
+}
+
+// connect binds to SMSC addresses in order, until one succeeds. Failure of each address,
+// except the last tried one, is notified to onFail.
+func connect(ctx context.Context, dialer Dialer, s Auth, bindReq *pdu.BindRequest, onFail ErrorCallback) (c *Connection, err error) {
+	endpoints := s.endpoints()
+	if len(endpoints) == 0 {
+		err = ErrNoEndpoint
 		return
 	}
 
-	c = NewConnection(conn)
+	for i, endpoint := range endpoints {
+		if c, err = bind(ctx, dialer, endpoint, s, bindReq); err == nil {
+			c.endpoint = endpoint
+			return
+		}
+		err = &EndpointError{Endpoint: endpoint, Err: err}
+
+		// no more chance if context is done
+		if ctx.Err() != nil || i == len(endpoints)-1 {
+			return
+		}
+
+		if onFail != nil {
+			onFail(err)
+		}
+	}
+
+	return
+}
+
+func bind(ctx context.Context, dialer Dialer, addr string, s Auth, bindReq *pdu.BindRequest) (c *Connection, err error) {
+	timeout := s.BindTimeout
+	if timeout <= 0 {
+		timeout = defaultBindTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	// send binding request
-	_, err = c.Write(marshal(bindReq))
+	conn, err := dialer(ctx, addr)
 	if err != nil {
-		_ = conn.Close()
 		return
 	}
 
-	// catching response
-	var (
-		p    pdu.PDU
-		resp *pdu.BindResp
-	)
+	c = NewConnection(conn)
+	c.SetSequenceGenerator(s.SequenceGenerator)
+	c.assignSequenceNumber(bindReq)
 
-	for {
-		if p, err = pdu.Parse(c); err != nil {
-			_ = conn.Close()
+	var resp *pdu.BindResp
+	if err = withContext(ctx, conn, func() (err error) {
+		// send binding request
+		if _, err = c.Write(marshal(bindReq)); err != nil {
 			return
 		}
 
-		if pd, ok := p.(*pdu.BindResp); ok {
-			resp = pd
-			break
+		// catching response
+		for {
+			var p pdu.PDU
+			if p, err = parse(c); err != nil {
+				return
+			}
+
+			if pd, ok := p.(*pdu.BindResp); ok {
+				resp = pd
+				return
+			}
 		}
+	}); err != nil {
+		_ = conn.Close()
+		return nil, err
 	}
 
-	if resp.CommandStatus != data.ESME_ROK {
-		err = fmt.Errorf("Binding error. Command status: [%d]. Please refer to: https://github.com/linxGnu/gosmpp/blob/master/data/pkg.go for more detail about this status code", resp.CommandStatus)
+	if err = pdu.ResponseError(resp); err != nil {
 		_ = conn.Close()
+		c = nil
 	} else {
 		c.systemID = resp.SystemID
 	}
 
 	return
 }
+
+// withContext runs I/O function on conn, bounded by context.
+// Blocked I/O is interrupted once context is done.
+func withContext(ctx context.Context, conn net.Conn, fn func() error) (err error) {
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		select {
+		case <-ctx.Done():
+			// deadline in the past interrupts blocked I/O
+			_ = conn.SetDeadline(time.Unix(1, 0))
+
+		case <-done:
+		}
+	}()
+
+	err = fn()
+	close(done)
+	<-stopped
+
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	if err == nil {
+		err = conn.SetDeadline(time.Time{})
+	}
+	return
+}
+
+// isTimeout checks if error is a network timeout.
+func isTimeout(err error) bool {
+	nErr, ok := err.(net.Error)
+	return ok && nErr.Timeout()
+}